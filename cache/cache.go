@@ -0,0 +1,38 @@
+// Package cache stores the JSON of computed aggregates under a key together
+// with tags naming what they were computed from, so that writes can drop
+// every aggregate they affect by tag.
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when nothing is cached under the key
+var ErrMiss = errors.New("cache: miss")
+
+// Cache is implemented by Redis, LRU and Tiered. Any error other than ErrMiss
+// means the cache could not be consulted.
+//
+// A value computed from the database may have been read before a write
+// committed and be stored after the write invalidated its tags. Callers
+// therefore take a Mark before computing a value and pass it to Set, which
+// leaves the value out if any of its tags was invalidated since.
+type Cache interface {
+	Get(key string) ([]byte, error)
+	// Mark returns how far the cache's invalidations have got
+	Mark() (Mark, error)
+	// Set stores data under key for ttl and tags the key with tags, unless
+	// one of tags was invalidated after since was taken
+	Set(since Mark, key string, data []byte, ttl time.Duration, tags ...string) error
+	// Invalidate drops every key tagged with any of tags
+	Invalidate(tags ...string) error
+}
+
+// Mark is a point in a cache's sequence of invalidations
+type Mark struct {
+	seq int64
+	// remote and local are the marks of the caches of a Tiered; remote is
+	// nil if it could not be used when the mark was taken
+	remote, local *Mark
+}
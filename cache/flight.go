@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errPanicked is returned to the callers waiting on a computation that panicked
+var errPanicked = errors.New("cache: computation panicked")
+
+// Group coalesces concurrent computations of the same key: while one caller
+// computes a key, the others asking for it wait for and share its result
+// instead of computing it again. The zero value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// Do runs fn for key unless a call for key is already in flight, in which
+// case it waits for that call and returns its result.
+func (g *Group) Do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.data, c.err
+	}
+	c := g.start(key)
+	g.mu.Unlock()
+
+	g.run(key, c, fn)
+	return c.data, c.err
+}
+
+// Go runs fn for key in the background unless a call for key is already in
+// flight. Callers of Do for key meanwhile wait for it. A panic in fn is
+// returned to them as an error, as no request is there to recover it.
+func (g *Group) Go(key string, fn func() ([]byte, error)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.calls[key]; ok {
+		return
+	}
+	c := g.start(key)
+	go g.run(key, c, func() (data []byte, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%w: %v", errPanicked, r)
+			}
+		}()
+		return fn()
+	})
+}
+
+// start registers a call for key; g.mu must be held
+func (g *Group) start(key string) *call {
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	c := &call{done: make(chan struct{})}
+	g.calls[key] = c
+	return c
+}
+
+// run completes c even if fn panics, so that waiters are not left blocked
+func (g *Group) run(key string, c *call, fn func() ([]byte, error)) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.err = errPanicked
+	c.data, c.err = fn()
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupDo(t *testing.T) {
+	errCompute := errors.New("compute failed")
+	tests := []struct {
+		name    string
+		callers int
+		data    []byte
+		err     error
+	}{
+		{"one caller", 1, []byte("a"), nil},
+		{"concurrent callers share a value", 10, []byte("a"), nil},
+		{"concurrent callers share an error", 10, nil, errCompute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var g Group
+			var calls int32
+			release := make(chan struct{})
+			fn := func() ([]byte, error) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return tt.data, tt.err
+			}
+
+			var wg sync.WaitGroup
+			results := make([]struct {
+				data []byte
+				err  error
+			}, tt.callers)
+			for i := 0; i < tt.callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i].data, results[i].err = g.Do("key", fn)
+				}(i)
+			}
+			// let every caller reach Do before the computation finishes
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			if calls != 1 {
+				t.Errorf("fn ran %d times, want once", calls)
+			}
+			for i, r := range results {
+				if string(r.data) != string(tt.data) || r.err != tt.err {
+					t.Errorf("caller %d got (%q, %v), want (%q, %v)", i, r.data, r.err, tt.data, tt.err)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupDoAfterCompletion(t *testing.T) {
+	var g Group
+	var calls int
+	fn := func() ([]byte, error) {
+		calls++
+		return nil, nil
+	}
+	g.Do("key", fn)
+	g.Do("key", fn)
+	if calls != 2 {
+		t.Errorf("fn ran %d times, want a fresh call once the first completed", calls)
+	}
+}
+
+func TestGroupDoKeys(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	var calls int32
+	fn := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil, nil
+	}
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			g.Do(key, fn)
+		}(key)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 2 {
+		t.Errorf("fn ran %d times, want once per key", calls)
+	}
+}
+
+func TestGroupGoPanic(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	g.Go("key", func() ([]byte, error) {
+		<-release
+		panic("boom")
+	})
+	done := make(chan error)
+	go func() {
+		_, err := g.Do("key", func() ([]byte, error) { return nil, nil })
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	if err := <-done; !errors.Is(err, errPanicked) {
+		t.Errorf("Do waiting on a panicked Go = %v, want errPanicked", err)
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU keeps the cache in process memory, evicting the least recently used
+// key once it holds size keys. It never returns an error other than ErrMiss.
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+	tags  map[string]map[string]bool // tag → keys
+	seq   int64
+	// invalidated holds the mark of the last invalidation of each tag. It
+	// grows with the tags ever invalidated, which are bounded by the
+	// players, teams and seasons.
+	invalidated map[string]int64
+}
+
+type lruEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+	tags    []string
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		order: list.New(),
+		items: map[string]*list.Element{},
+		tags:  map[string]map[string]bool{},
+
+		invalidated: map[string]int64{},
+	}
+}
+
+func (c *LRU) Get(key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, ErrMiss
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(el)
+		return nil, ErrMiss
+	}
+	c.order.MoveToFront(el)
+	return entry.data, nil
+}
+
+func (c *LRU) Mark() (Mark, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Mark{seq: c.seq}, nil
+}
+
+func (c *LRU) Set(since Mark, key string, data []byte, ttl time.Duration, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		if c.invalidated[tag] > since.seq {
+			return nil
+		}
+	}
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	entry := &lruEntry{key: key, data: data, expires: time.Now().Add(ttl), tags: tags}
+	c.items[key] = c.order.PushFront(entry)
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = map[string]bool{}
+		}
+		c.tags[tag][key] = true
+	}
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *LRU) Invalidate(tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	for _, tag := range tags {
+		c.invalidated[tag] = c.seq
+		for key := range c.tags[tag] {
+			if el, ok := c.items[key]; ok {
+				c.remove(el)
+			}
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+// remove drops an entry and its tag memberships. The caller holds mu.
+func (c *LRU) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*lruEntry)
+	delete(c.items, entry.key)
+	for _, tag := range entry.tags {
+		delete(c.tags[tag], entry.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func set(t *testing.T, c Cache, key string, tags ...string) {
+	t.Helper()
+	mark, err := c.Mark()
+	if err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	if err := c.Set(mark, key, []byte(key), time.Hour, tags...); err != nil {
+		t.Fatalf("Set(%q): %v", key, err)
+	}
+}
+
+// cached returns the keys among keys that c holds
+func cached(c Cache, keys ...string) map[string]bool {
+	held := map[string]bool{}
+	for _, key := range keys {
+		if _, err := c.Get(key); err == nil {
+			held[key] = true
+		}
+	}
+	return held
+}
+
+func sameKeys(got map[string]bool, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for _, key := range want {
+		if !got[key] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		sets []string
+		gets []string // read between the first sets and the last one
+		last string
+		want []string
+	}{
+		{"under size", 3, []string{"a", "b"}, nil, "c", []string{"a", "b", "c"}},
+		{"oldest evicted", 2, []string{"a", "b"}, nil, "c", []string{"b", "c"}},
+		{"read keeps a key", 2, []string{"a", "b"}, []string{"a"}, "c", []string{"a", "c"}},
+		{"rewrite keeps a key", 2, []string{"a", "b", "a"}, nil, "c", []string{"a", "c"}},
+		{"size one", 1, []string{"a"}, []string{"a"}, "b", []string{"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU(tt.size)
+			for _, key := range tt.sets {
+				set(t, c, key)
+			}
+			for _, key := range tt.gets {
+				c.Get(key)
+			}
+			set(t, c, tt.last)
+			if got := cached(c, "a", "b", "c"); !sameKeys(got, tt.want) {
+				t.Errorf("cached = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLRUExpiry(t *testing.T) {
+	c := NewLRU(10)
+	mark, _ := c.Mark()
+	c.Set(mark, "a", []byte("a"), -time.Second)
+	if _, err := c.Get("a"); err != ErrMiss {
+		t.Errorf("Get of an expired key = %v, want ErrMiss", err)
+	}
+}
+
+func TestLRUInvalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate []string
+		want       []string
+	}{
+		{"nothing", nil, []string{"a", "b", "c", "d"}},
+		{"unknown tag", []string{"team:9"}, []string{"a", "b", "c", "d"}},
+		{"one tag", []string{"player:1"}, []string{"c", "d"}},
+		{"shared tag", []string{"team:1"}, []string{"c", "d"}},
+		{"tag of one key", []string{"season:2024"}, []string{"a", "c", "d"}},
+		{"several tags", []string{"player:1", "team:2"}, []string{"d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU(10)
+			set(t, c, "a", "player:1", "team:1")
+			set(t, c, "b", "player:1", "team:1", "season:2024")
+			set(t, c, "c", "player:2", "team:2")
+			set(t, c, "d")
+			if err := c.Invalidate(tt.invalidate...); err != nil {
+				t.Fatalf("Invalidate: %v", err)
+			}
+			if got := cached(c, "a", "b", "c", "d"); !sameKeys(got, tt.want) {
+				t.Errorf("cached = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLRUSetAfterInvalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		tags       []string
+		invalidate string
+		want       bool
+	}{
+		{"tag invalidated", []string{"player:1", "team:1"}, "team:1", false},
+		{"other tag invalidated", []string{"player:1"}, "player:2", true},
+		{"untagged", nil, "player:1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU(10)
+			mark, _ := c.Mark()
+			c.Invalidate(tt.invalidate)
+			c.Set(mark, "a", []byte("a"), time.Hour, tt.tags...)
+			if got := cached(c, "a")["a"]; got != tt.want {
+				t.Errorf("cached = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Redis keeps the cache in Redis, shared by every instance of the service. A
+// tag is a Redis set of the keys tagged with it.
+type Redis struct {
+	rdb *redis.Client
+}
+
+func NewRedis(rdb *redis.Client) *Redis {
+	return &Redis{rdb: rdb}
+}
+
+func tagKey(tag string) string { return "tag:" + tag }
+
+// invalidatedKey holds the mark of the last invalidation of a tag
+func invalidatedKey(tag string) string { return "invalidated:" + tag }
+
+// markKey counts the invalidations, shared by every instance
+const markKey = "cache:mark"
+
+// invalidatedTTL is how long the last invalidation of a tag is remembered,
+// far longer than computing any value takes
+const invalidatedTTL = time.Hour
+
+// setScript stores ARGV[2] under KEYS[1] for ARGV[3] milliseconds unless one
+// of the invalidated keys in the second half of the other KEYS is past the
+// mark ARGV[1]. It adds KEYS[1] to the tag sets in the first half and extends
+// their TTL, never shortening it: a tag that expired before a key tagged with
+// it would leave the key out of reach of Invalidate.
+var setScript = redis.NewScript(`
+local n = (#KEYS - 1) / 2
+for i = 1, n do
+	local invalidated = redis.call('GET', KEYS[1 + n + i])
+	if invalidated and tonumber(invalidated) > tonumber(ARGV[1]) then
+		return 0
+	end
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+for i = 1, n do
+	redis.call('SADD', KEYS[1 + i], KEYS[1])
+	if redis.call('PTTL', KEYS[1 + i]) < tonumber(ARGV[3]) then
+		redis.call('PEXPIRE', KEYS[1 + i], ARGV[3])
+	end
+end
+return 1`)
+
+func (c *Redis) Get(key string) ([]byte, error) {
+	data, err := c.rdb.Get(key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrMiss
+	}
+	return data, err
+}
+
+func (c *Redis) Mark() (Mark, error) {
+	seq, err := c.rdb.Get(markKey).Int64()
+	if err == redis.Nil {
+		return Mark{}, nil
+	}
+	return Mark{seq: seq}, err
+}
+
+// Set adds key to each of its tags. A tag lives as long as the longest-lived
+// key tagged with it.
+func (c *Redis) Set(since Mark, key string, data []byte, ttl time.Duration, tags ...string) error {
+	keys := make([]string, 1, 1+2*len(tags))
+	keys[0] = key
+	for _, tag := range tags {
+		keys = append(keys, tagKey(tag))
+	}
+	for _, tag := range tags {
+		keys = append(keys, invalidatedKey(tag))
+	}
+	return setScript.Run(c.rdb, keys, since.seq, data, int64(ttl/time.Millisecond)).Err()
+}
+
+// Invalidate records the invalidation before removing the keys, so that a
+// Set racing with it either sees it or is removed by it. It only removes the
+// keys it read from a tag, so a key cached meanwhile keeps its tag.
+func (c *Redis) Invalidate(tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	seq, err := c.rdb.Incr(markKey).Result()
+	if err != nil {
+		return err
+	}
+	_, err = c.rdb.Pipelined(func(pipe redis.Pipeliner) error {
+		for _, tag := range tags {
+			pipe.Set(invalidatedKey(tag), seq, invalidatedTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		keys, err := c.rdb.SMembers(tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		members := make([]interface{}, len(keys))
+		for i, k := range keys {
+			members[i] = k
+		}
+		_, err = c.rdb.TxPipelined(func(pipe redis.Pipeliner) error {
+			pipe.Del(keys...)
+			pipe.SRem(tagKey(tag), members...)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
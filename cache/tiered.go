@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Tiered uses a remote cache while it is reachable and degrades to a local
+// one when it is not. After a remote error the remote cache is skipped for
+// the cooldown, so requests do not each wait out a connection timeout.
+//
+// Tags invalidated while the remote cache is down are kept and invalidated
+// there before it is used again, so it never serves what a write made stale
+// during the outage. The local cache only sees this instance's writes, which
+// is the price of staying up.
+type Tiered struct {
+	remote   Cache
+	local    Cache
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+	pending   map[string]bool // tags still to invalidate remotely
+}
+
+func NewTiered(remote, local Cache, cooldown time.Duration) *Tiered {
+	return &Tiered{remote: remote, local: local, cooldown: cooldown, pending: map[string]bool{}}
+}
+
+func (c *Tiered) Get(key string) ([]byte, error) {
+	if c.remoteUp() {
+		data, err := c.remote.Get(key)
+		if err == nil || err == ErrMiss {
+			return data, err
+		}
+		c.markDown(err)
+	}
+	return c.local.Get(key)
+}
+
+// Mark takes the marks of both caches, as Set may fall back to the local one
+func (c *Tiered) Mark() (Mark, error) {
+	local, err := c.local.Mark()
+	if err != nil {
+		return Mark{}, err
+	}
+	m := Mark{local: &local}
+	if c.remoteUp() {
+		remote, err := c.remote.Mark()
+		if err == nil {
+			m.remote = &remote
+		} else {
+			c.markDown(err)
+		}
+	}
+	return m, nil
+}
+
+// Set skips the remote cache if it was down when since was taken, as it may
+// have missed invalidations the value predates.
+func (c *Tiered) Set(since Mark, key string, data []byte, ttl time.Duration, tags ...string) error {
+	if c.remoteUp() {
+		if since.remote == nil {
+			return nil
+		}
+		err := c.remote.Set(*since.remote, key, data, ttl, tags...)
+		if err == nil {
+			return nil
+		}
+		c.markDown(err)
+	}
+	if since.local == nil {
+		return nil
+	}
+	return c.local.Set(*since.local, key, data, ttl, tags...)
+}
+
+// Invalidate always drops the tags locally too, as the local cache may hold
+// keys from an earlier outage.
+func (c *Tiered) Invalidate(tags ...string) error {
+	if err := c.local.Invalidate(tags...); err != nil {
+		return err
+	}
+	if c.remoteUp() {
+		err := c.remote.Invalidate(tags...)
+		if err == nil {
+			return nil
+		}
+		c.markDown(err)
+	}
+	c.mu.Lock()
+	for _, tag := range tags {
+		c.pending[tag] = true
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// remoteUp reports whether the remote cache may be used, first invalidating
+// the tags left over from an outage.
+func (c *Tiered) remoteUp() bool {
+	c.mu.Lock()
+	if time.Now().Before(c.downUntil) {
+		c.mu.Unlock()
+		return false
+	}
+	var tags []string
+	for tag := range c.pending {
+		tags = append(tags, tag)
+	}
+	c.mu.Unlock()
+	if len(tags) == 0 {
+		return true
+	}
+
+	if err := c.remote.Invalidate(tags...); err != nil {
+		c.markDown(err)
+		return false
+	}
+	c.mu.Lock()
+	for _, tag := range tags {
+		delete(c.pending, tag)
+	}
+	c.mu.Unlock()
+	return true
+}
+
+func (c *Tiered) markDown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.downUntil) {
+		return
+	}
+	c.downUntil = time.Now().Add(c.cooldown)
+	log.Printf("Remote cache unreachable, using the local cache for %s: %v\n", c.cooldown, err)
+}
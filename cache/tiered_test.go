@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("down")
+
+// flaky is an LRU standing in for Redis that fails every call while down
+type flaky struct {
+	*LRU
+	down bool
+}
+
+func (c *flaky) Get(key string) ([]byte, error) {
+	if c.down {
+		return nil, errDown
+	}
+	return c.LRU.Get(key)
+}
+
+func (c *flaky) Mark() (Mark, error) {
+	if c.down {
+		return Mark{}, errDown
+	}
+	return c.LRU.Mark()
+}
+
+func (c *flaky) Set(since Mark, key string, data []byte, ttl time.Duration, tags ...string) error {
+	if c.down {
+		return errDown
+	}
+	return c.LRU.Set(since, key, data, ttl, tags...)
+}
+
+func (c *flaky) Invalidate(tags ...string) error {
+	if c.down {
+		return errDown
+	}
+	return c.LRU.Invalidate(tags...)
+}
+
+func TestTieredFailover(t *testing.T) {
+	remote := &flaky{LRU: NewLRU(10)}
+	local := NewLRU(10)
+	c := NewTiered(remote, local, time.Hour)
+
+	set(t, c, "a", "player:1")
+	if !sameKeys(cached(remote, "a"), []string{"a"}) || len(cached(local, "a")) != 0 {
+		t.Fatalf("with Redis up the value should only be cached remotely")
+	}
+
+	remote.down = true
+	if _, err := c.Get("a"); err != ErrMiss {
+		t.Errorf("Get with Redis down = %v, want a local ErrMiss", err)
+	}
+	set(t, c, "b", "player:1")
+	if _, err := c.Get("b"); err != nil {
+		t.Errorf("Get with Redis down = %v, want the locally cached value", err)
+	}
+
+	// the cooldown keeps Redis skipped after it comes back
+	remote.down = false
+	if _, err := c.Get("b"); err != nil {
+		t.Errorf("Get during the cooldown = %v, want the locally cached value", err)
+	}
+}
+
+func TestTieredRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		invalidate []string // while Redis is down
+		want       []string // cached remotely once it is back
+	}{
+		{"no writes", nil, []string{"a", "b"}},
+		{"write to one tag", []string{"player:1"}, []string{"b"}},
+		{"writes to both tags", []string{"player:1", "player:2"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := &flaky{LRU: NewLRU(10)}
+			c := NewTiered(remote, NewLRU(10), 0)
+			set(t, c, "a", "player:1")
+			set(t, c, "b", "player:2")
+
+			remote.down = true
+			c.Get("a") // notices the outage
+			if err := c.Invalidate(tt.invalidate...); err != nil {
+				t.Fatalf("Invalidate with Redis down: %v", err)
+			}
+
+			remote.down = false
+			if got := cached(c, "a", "b"); !sameKeys(got, tt.want) {
+				t.Errorf("cached after recovery = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTieredSetAfterOutage(t *testing.T) {
+	remote := &flaky{LRU: NewLRU(10), down: true}
+	c := NewTiered(remote, NewLRU(10), 0)
+
+	// a value computed while Redis was down may predate writes it missed
+	mark, err := c.Mark()
+	if err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	remote.down = false
+	if err := c.Set(mark, "a", []byte("a"), time.Hour, "player:1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if len(cached(remote, "a")) != 0 {
+		t.Errorf("a value marked during the outage was cached remotely")
+	}
+}
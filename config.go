@@ -5,24 +5,32 @@ import (
 )
 
 type Config struct {
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBHost     string
-	DBPort     string
-	RedisHost  string
-	RedisPort  string
+	DBUser      string
+	DBPassword  string
+	DBName      string
+	DBHost      string
+	DBPort      string
+	RedisHost   string
+	RedisPort   string
+	CacheMode   string // redis, lru or tiered
+	CacheSize   string // keys kept by the in-process LRU
+	CacheStale  string // how long expired aggregates are served while they are recomputed, e.g. 10m
+	PERInterval string // how often seasons whose data changed get their PER recomputed
 }
 
 func LoadConfig() Config {
 	return Config{
-		DBUser:     getEnv("DB_USER", "your_default_db_user"),
-		DBPassword: getEnv("DB_PASSWORD", "your_default_db_password"),
-		DBName:     getEnv("DB_NAME", "your_default_db_name"),
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		RedisHost:  getEnv("REDIS_HOST", "localhost"),
-		RedisPort:  getEnv("REDIS_PORT", "6379"),
+		DBUser:      getEnv("DB_USER", "your_default_db_user"),
+		DBPassword:  getEnv("DB_PASSWORD", "your_default_db_password"),
+		DBName:      getEnv("DB_NAME", "your_default_db_name"),
+		DBHost:      getEnv("DB_HOST", "localhost"),
+		DBPort:      getEnv("DB_PORT", "5432"),
+		RedisHost:   getEnv("REDIS_HOST", "localhost"),
+		RedisPort:   getEnv("REDIS_PORT", "6379"),
+		CacheMode:   getEnv("CACHE_MODE", "tiered"),
+		CacheSize:   getEnv("CACHE_SIZE", "10000"),
+		CacheStale:  getEnv("CACHE_STALE", "0s"),
+		PERInterval: getEnv("PER_INTERVAL", "1m"),
 	}
 }
 
@@ -52,6 +52,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -63,7 +69,7 @@ const docTemplate = `{
         },
         "/add-stat": {
             "post": {
-                "description": "Add a new game stat to the database",
+                "description": "Add a new game stat to the database. game_id is required and the stat line takes the game's date. Responds with the stat line and its id.",
                 "consumes": [
                     "application/json"
                 ],
@@ -98,6 +104,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -107,23 +119,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/players": {
+        "/conferences": {
             "get": {
-                "description": "Get a list of all players",
+                "description": "Get a list of all conferences",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "leagues"
                 ],
-                "summary": "List all players",
+                "summary": "List all conferences",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Player"
+                                "$ref": "#/definitions/models.Conference"
                             }
                         }
                     },
@@ -134,34 +146,154 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "description": "Add a conference to a league",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leagues"
+                ],
+                "summary": "Add a new conference",
+                "parameters": [
+                    {
+                        "description": "Conference",
+                        "name": "conference",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Conference"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Conference"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
             }
         },
-        "/stat/players/{playerId}": {
+        "/divisions": {
             "get": {
-                "description": "Get a list of all players",
+                "description": "Get a list of all divisions",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "leagues"
                 ],
-                "summary": "player stats",
+                "summary": "List all divisions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Division"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a division to a conference",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leagues"
+                ],
+                "summary": "Add a new division",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "PlayerId",
-                        "name": "playerId",
-                        "in": "path",
-                        "required": true
+                        "description": "Division",
+                        "name": "division",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Division"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Division"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
+                }
+            }
+        },
+        "/games": {
+            "get": {
+                "description": "Get a list of all games ordered by date",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "List all games",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.AvgStat"
+                                "$ref": "#/definitions/models.Game"
                             }
                         }
                     },
@@ -172,23 +304,73 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "description": "Add a new game between two teams",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Add a new game",
+                "parameters": [
+                    {
+                        "description": "Game",
+                        "name": "game",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
             }
         },
-        "/stat/teams/{teamId}": {
+        "/games/{gameId}": {
             "get": {
-                "description": "Get a list of all players",
+                "description": "Get a single game by id",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "players"
+                    "games"
                 ],
-                "summary": "team stats",
+                "summary": "Get a game",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "teamId",
-                        "name": "teamId",
+                        "description": "gameId",
+                        "name": "gameId",
                         "in": "path",
                         "required": true
                     }
@@ -197,10 +379,127 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.AvgStat"
-                            }
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid game ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the teams, date, score and status of a game",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Update a game",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "gameId",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Game",
+                        "name": "game",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a game that has no player or team stat lines recorded against it",
+                "tags": [
+                    "games"
+                ],
+                "summary": "Delete a game",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "gameId",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid game ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Game has stat lines",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -211,45 +510,2419 @@ const docTemplate = `{
                     }
                 }
             }
-        }
+        },
+        "/games/{gameId}/team-stats": {
+            "get": {
+                "description": "Get the team box scores recorded for a game",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Team box scores of a game",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "gameId",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TeamGameStat"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid game ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/games/{gameId}/team-stats/{teamId}": {
+            "put": {
+                "description": "Create or replace a team's box score for a game. Totals include team rebounds and team turnovers, which are also given on their own.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "games"
+                ],
+                "summary": "Record a team box score",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "gameId",
+                        "name": "gameId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "teamId",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Team box score",
+                        "name": "stat",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamGameStat"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamGameStat"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Game not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/leagues": {
+            "get": {
+                "description": "Get a list of all leagues",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leagues"
+                ],
+                "summary": "List all leagues",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.League"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a league, the top of the league → conference → division → team hierarchy. Rules left out default to the NBA's: four 12-minute periods, 5-minute overtimes and a six-foul limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leagues"
+                ],
+                "summary": "Add a new league",
+                "parameters": [
+                    {
+                        "description": "League",
+                        "name": "league",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.League"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.League"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/players": {
+            "get": {
+                "description": "Get a list of all players",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "List all players",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Player"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{playerId}": {
+            "get": {
+                "description": "Get a player's bio together with career and current regular season averages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Player profile",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "PlayerId",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlayerProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid player ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/players/{playerId}/teams": {
+            "get": {
+                "description": "Get every stint the player has had with a team, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Player team history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "PlayerId",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PlayerStint"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid player ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/reconciliation": {
+            "get": {
+                "description": "Check every final game against its lines: the points of each team's players, credited by the team they played for on the game date, and the team box score where one is recorded, must both equal the team's final score. Lists the sides that do not.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Score reconciliation report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs",
+                        "name": "season_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First game date, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last game date, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DataQualityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/seasons": {
+            "get": {
+                "description": "Get a list of all seasons ordered by start date",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "seasons"
+                ],
+                "summary": "List all seasons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Season"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a season phase with the date range its games are played in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "seasons"
+                ],
+                "summary": "Add a new season",
+                "parameters": [
+                    {
+                        "description": "Season",
+                        "name": "season",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Season"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Season"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/games/{externalId}": {
+            "put": {
+                "description": "Create or update the game a provider's id is mapped to. An unmapped id creates a game, unless the body gives the id of an existing game, which links the two.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external ids"
+                ],
+                "summary": "Upsert a game by external id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The provider's id",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Game",
+                        "name": "game",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Game"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/players/{externalId}": {
+            "put": {
+                "description": "Create or update the player a provider's id is mapped to. An unmapped id creates a player, unless the body gives the id of an existing player, which links the two so that feeds from different providers converge on one record. An existing player's team is only changed through transactions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external ids"
+                ],
+                "summary": "Upsert a player by external id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The provider's id",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Player",
+                        "name": "player",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Player"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/teams/{externalId}": {
+            "put": {
+                "description": "Create or update the team a provider's id is mapped to. An unmapped id creates a team, unless the body gives the id of an existing team, which links the two.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external ids"
+                ],
+                "summary": "Upsert a team by external id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The provider's id",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Team",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sources/{source}/{entity}/{externalId}": {
+            "get": {
+                "description": "Get the record a provider's id is mapped to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "external ids"
+                ],
+                "summary": "Resolve an external id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider name",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "players, teams or games",
+                        "name": "entity",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The provider's id",
+                        "name": "externalId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ExternalID"
+                        }
+                    },
+                    "404": {
+                        "description": "External id not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/standings": {
+            "get": {
+                "description": "Get conference standings derived from final game scores. Defaults to the current regular season.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Standings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs (default regular)",
+                        "name": "season_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Standings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Season not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/standings/seeds": {
+            "get": {
+                "description": "Get every conference's teams in seed order, with ties broken by the NBA tiebreak procedure. Each seed names the rule that put it ahead of the next team when they finished with the same record. Defaults to the current regular season.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Conference seeds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs (default regular)",
+                        "name": "season_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeedList"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Season not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/per": {
+            "get": {
+                "description": "Get every player's unadjusted, pace-adjusted and league-scaled PER over a season, highest first, with the league constants they were computed with. PER is recomputed in the background after the season's data changes; league.stale is set while a recompute is pending.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Player Efficiency Rating",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24. Defaults to the current season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs. Defaults to regular",
+                        "name": "season_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SeasonPER"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Season not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "PER for the season is still being computed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/players/{playerId}": {
+            "get": {
+                "description": "Get a list of all players",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "player stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "PlayerId",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs",
+                        "name": "season_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period number (1-4 quarters, 5+ overtimes) or ot for all overtimes",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First game date, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last game date, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the last N games matching the other filters",
+                        "name": "last_n",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "game (default), 36 for per 36 minutes, 100poss for per 100 possessions, or total",
+                        "name": "per",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AvgStat"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/players/{playerId}/advanced": {
+            "get": {
+                "description": "Get a player's efficiency metrics: true shooting, effective field goal, usage, assist, rebound and turnover percentages and the average game score. Team context comes from the games the player played, using each team's recorded box score where there is one and the sum of its players' lines otherwise.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "player advanced stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "PlayerId",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs",
+                        "name": "season_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First game date, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last game date, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the last N games matching the other filters",
+                        "name": "last_n",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AdvancedStat"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/players/{playerId}/distribution": {
+            "get": {
+                "description": "Get the sum, count, median, sample standard deviation, min, max and percentiles of every box score column over a player's stat lines, to show how consistent the player is",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "player stat distribution",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "PlayerId",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs",
+                        "name": "season_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period number (1-4 quarters, 5+ overtimes) or ot for all overtimes",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First game date, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last game date, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the last N games matching the other filters",
+                        "name": "last_n",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated percentiles between 0 and 100, by default 10,25,75,90",
+                        "name": "percentiles",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StatDistribution"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stat/teams/{teamId}": {
+            "get": {
+                "description": "Get a list of all players",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "team stats",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "teamId",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season year, e.g. 2024 for 2023-24",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "preseason, regular, play-in or playoffs",
+                        "name": "season_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Period number (1-4 quarters, 5+ overtimes) or ot for all overtimes",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First game date, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last game date, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only the last N games matching the other filters",
+                        "name": "last_n",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "game (default), 36 for per 36 minutes, 100poss for per 100 possessions, or total",
+                        "name": "per",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AvgStat"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/{id}": {
+            "delete": {
+                "description": "Delete a stat line together with its period breakdown",
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Delete a game stat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stat line id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid stat ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Stat line not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update the fields given in the body of a stat line. Giving periods replaces the period breakdown, and when no game totals are given alongside them the totals are rolled up from the new periods. Rebounds follow a changed split unless given. The result is validated like a new stat line.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Correct a game stat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stat line id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "stat",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GameStat"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GameStat"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Stat line not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams": {
+            "get": {
+                "description": "Get a list of all teams",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "List all teams",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Team"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new team to the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Add a new team",
+                "parameters": [
+                    {
+                        "description": "Team",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/teams/{teamId}": {
+            "get": {
+                "description": "Get a team together with its current roster",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Get a team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "teamId",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid team ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the name, city and division of a team",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Update a team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "teamId",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Team",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a team that has no current or past players and no games",
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Delete a team",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "teamId",
+                        "name": "teamId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid team ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Team not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Team is still referenced",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "post": {
+                "description": "Close the player's current stint on effective_date and open one with to_team_id. A to_team_id of 0 releases the player. A player without a team can only be signed on or after the end of the last stint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "players"
+                ],
+                "summary": "Move a player to another team",
+                "parameters": [
+                    {
+                        "description": "Transaction",
+                        "name": "transaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation failed, including a move to the player's own team, a release of a player without a team and an effective_date that overlaps an earlier stint",
+                        "schema": {
+                            "$ref": "#/definitions/models.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "models.AdvancedStat": {
+            "type": "object",
+            "properties": {
+                "assist_pct": {
+                    "description": "AssistPct is the share of teammates' field goals the player assisted\nwhile on the floor",
+                    "type": "number"
+                },
+                "effective_field_goal_pct": {
+                    "type": "number"
+                },
+                "game_score": {
+                    "description": "GameScore is Hollinger's game score averaged per game",
+                    "type": "number"
+                },
+                "games_played": {
+                    "type": "integer"
+                },
+                "rebound_pct": {
+                    "description": "ReboundPct is the share of available rebounds the player grabbed while\non the floor",
+                    "type": "number"
+                },
+                "true_shooting_pct": {
+                    "type": "number"
+                },
+                "turnover_pct": {
+                    "type": "number"
+                },
+                "usage_pct": {
+                    "description": "UsagePct is the share of the team's possessions the player used while\non the floor",
+                    "type": "number"
+                }
+            }
+        },
         "models.AvgStat": {
             "type": "object",
             "properties": {
-                "avg_assists": {
-                    "type": "number"
+                "avg_assists": {
+                    "type": "number"
+                },
+                "avg_blocks": {
+                    "type": "number"
+                },
+                "avg_defensive_rebounds": {
+                    "type": "number"
+                },
+                "avg_field_goals_attempted": {
+                    "type": "number"
+                },
+                "avg_field_goals_made": {
+                    "type": "number"
+                },
+                "avg_fouls": {
+                    "type": "number"
+                },
+                "avg_free_throws_attempted": {
+                    "type": "number"
+                },
+                "avg_free_throws_made": {
+                    "type": "number"
+                },
+                "avg_minutes_played": {
+                    "type": "number"
+                },
+                "avg_offensive_rebounds": {
+                    "type": "number"
+                },
+                "avg_plus_minus": {
+                    "type": "number"
+                },
+                "avg_points": {
+                    "type": "number"
+                },
+                "avg_rebounds": {
+                    "type": "number"
+                },
+                "avg_steals": {
+                    "type": "number"
+                },
+                "avg_three_pointers_attempted": {
+                    "type": "number"
+                },
+                "avg_three_pointers_made": {
+                    "type": "number"
+                },
+                "avg_turnovers": {
+                    "type": "number"
+                },
+                "field_goal_pct": {
+                    "description": "Shooting percentages are total makes over total attempts, not the\naverage of each game's percentage.",
+                    "type": "number"
+                },
+                "free_throw_pct": {
+                    "type": "number"
+                },
+                "games_played": {
+                    "type": "integer"
+                },
+                "games_started": {
+                    "type": "integer"
+                },
+                "per": {
+                    "description": "game, 36, 100poss or total",
+                    "type": "string"
+                },
+                "per_minutes": {
+                    "description": "PerMinutes is the minutes per 36 scales to under the leagues' rules,\n36 for NBA games",
+                    "type": "number"
+                },
+                "three_point_pct": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Conference": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "league_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConferenceSeeds": {
+            "type": "object",
+            "properties": {
+                "conference_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "seeds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Seed"
+                    }
+                }
+            }
+        },
+        "models.ConferenceStandings": {
+            "type": "object",
+            "properties": {
+                "conference_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "teams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TeamRecord"
+                    }
+                }
+            }
+        },
+        "models.DataQualityReport": {
+            "type": "object",
+            "properties": {
+                "games_checked": {
+                    "type": "integer"
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReconciliationIssue"
+                    }
+                }
+            }
+        },
+        "models.Distribution": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "median": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "percentiles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "stddev": {
+                    "type": "number"
+                },
+                "sum": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Division": {
+            "type": "object",
+            "properties": {
+                "conference_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExternalID": {
+            "type": "object",
+            "properties": {
+                "entity": {
+                    "type": "string"
+                },
+                "external_id": {
+                    "type": "string"
+                },
+                "internal_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Game": {
+            "type": "object",
+            "properties": {
+                "away_score": {
+                    "type": "integer"
+                },
+                "away_team_id": {
+                    "type": "integer"
+                },
+                "game_date": {
+                    "type": "string"
+                },
+                "home_score": {
+                    "type": "integer"
+                },
+                "home_team_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "overtime_periods": {
+                    "description": "number of overtime periods played",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "scheduled, in_progress, final or postponed",
+                    "type": "string"
+                }
+            }
+        },
+        "models.GameStat": {
+            "type": "object",
+            "properties": {
+                "assists": {
+                    "type": "integer"
+                },
+                "blocks": {
+                    "type": "integer"
+                },
+                "defensive_rebounds": {
+                    "type": "integer"
+                },
+                "field_goals_attempted": {
+                    "type": "integer"
+                },
+                "field_goals_made": {
+                    "type": "integer"
+                },
+                "fouls": {
+                    "type": "integer"
+                },
+                "free_throws_attempted": {
+                    "type": "integer"
+                },
+                "free_throws_made": {
+                    "type": "integer"
+                },
+                "game_date": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "minutes_played": {
+                    "type": "number"
+                },
+                "offensive_rebounds": {
+                    "type": "integer"
+                },
+                "periods": {
+                    "description": "Periods optionally breaks the line down by period. When given, the game\ntotals are rolled up from them if omitted and must match them otherwise.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PeriodStat"
+                    }
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "plus_minus": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "rebounds": {
+                    "description": "must equal offensive + defensive; derived when omitted",
+                    "type": "integer"
+                },
+                "started": {
+                    "type": "boolean"
+                },
+                "steals": {
+                    "type": "integer"
+                },
+                "three_pointers_attempted": {
+                    "type": "integer"
+                },
+                "three_pointers_made": {
+                    "type": "integer"
+                },
+                "turnovers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.League": {
+            "type": "object",
+            "properties": {
+                "foul_limit": {
+                    "description": "personal fouls that disqualify a player",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "overtime_minutes": {
+                    "type": "number"
+                },
+                "period_minutes": {
+                    "type": "number"
+                },
+                "periods": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LeagueConstants": {
+            "type": "object",
+            "properties": {
+                "average_aper": {
+                    "description": "AverageAPER is the minute-weighted league average of aPER, which PER\nscales to 15",
+                    "type": "number"
+                },
+                "computed_at": {
+                    "type": "string"
+                },
+                "defensive_rebound_pct": {
+                    "type": "number"
+                },
+                "factor": {
+                    "type": "number"
+                },
+                "free_throw_attempts_per_foul": {
+                    "type": "number"
+                },
+                "free_throws_per_foul": {
+                    "type": "number"
+                },
+                "pace": {
+                    "description": "possessions per 48 minutes",
+                    "type": "number"
+                },
+                "stale": {
+                    "description": "Stale is set when the season's data changed since the constants were\ncomputed and a recompute is pending",
+                    "type": "boolean"
+                },
+                "value_of_possession": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PeriodStat": {
+            "type": "object",
+            "properties": {
+                "assists": {
+                    "type": "integer"
+                },
+                "blocks": {
+                    "type": "integer"
+                },
+                "defensive_rebounds": {
+                    "type": "integer"
+                },
+                "field_goals_attempted": {
+                    "type": "integer"
+                },
+                "field_goals_made": {
+                    "type": "integer"
+                },
+                "fouls": {
+                    "type": "integer"
+                },
+                "free_throws_attempted": {
+                    "type": "integer"
+                },
+                "free_throws_made": {
+                    "type": "integer"
+                },
+                "minutes_played": {
+                    "type": "number"
+                },
+                "offensive_rebounds": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "integer"
+                },
+                "plus_minus": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "rebounds": {
+                    "description": "must equal offensive + defensive; derived when omitted",
+                    "type": "integer"
+                },
+                "steals": {
+                    "type": "integer"
+                },
+                "three_pointers_attempted": {
+                    "type": "integer"
+                },
+                "three_pointers_made": {
+                    "type": "integer"
+                },
+                "turnovers": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Player": {
+            "type": "object",
+            "properties": {
+                "birth_date": {
+                    "type": "string"
+                },
+                "draft_year": {
+                    "type": "integer"
+                },
+                "height_cm": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "jersey_number": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "nationality": {
+                    "description": "ISO 3166-1 alpha-3 country code",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "PG, SG, SF, PF, C, G, F or a hybrid such as G-F",
+                    "type": "string"
+                },
+                "team_id": {
+                    "description": "New field for foreign key",
+                    "type": "integer"
+                },
+                "weight_kg": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlayerPER": {
+            "type": "object",
+            "properties": {
+                "aper": {
+                    "type": "number"
+                },
+                "minutes_played": {
+                    "type": "number"
+                },
+                "per": {
+                    "type": "number"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "player_name": {
+                    "type": "string"
+                },
+                "uper": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PlayerProfile": {
+            "type": "object",
+            "properties": {
+                "birth_date": {
+                    "type": "string"
                 },
-                "avg_blocks": {
-                    "type": "number"
+                "career": {
+                    "$ref": "#/definitions/models.AvgStat"
                 },
-                "avg_fouls": {
-                    "type": "number"
+                "current_season": {
+                    "$ref": "#/definitions/models.AvgStat"
+                },
+                "draft_year": {
+                    "type": "integer"
+                },
+                "height_cm": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
                 },
-                "avg_game_date": {
+                "jersey_number": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "avg_minutes_played": {
-                    "type": "number"
+                "nationality": {
+                    "description": "ISO 3166-1 alpha-3 country code",
+                    "type": "string"
                 },
-                "avg_points": {
-                    "type": "number"
+                "position": {
+                    "description": "PG, SG, SF, PF, C, G, F or a hybrid such as G-F",
+                    "type": "string"
                 },
-                "avg_rebounds": {
-                    "type": "number"
+                "team_id": {
+                    "description": "New field for foreign key",
+                    "type": "integer"
                 },
-                "avg_steals": {
+                "weight_kg": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlayerStint": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReconciliationIssue": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "type": "string"
+                },
+                "final_score": {
+                    "type": "integer"
+                },
+                "game_date": {
+                    "type": "string"
+                },
+                "game_id": {
+                    "type": "integer"
+                },
+                "player_lines": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Season": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "preseason, regular, play-in or playoffs",
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SeasonPER": {
+            "type": "object",
+            "properties": {
+                "league": {
+                    "$ref": "#/definitions/models.LeagueConstants"
+                },
+                "players": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlayerPER"
+                    }
+                },
+                "season": {
+                    "$ref": "#/definitions/models.Season"
+                }
+            }
+        },
+        "models.Seed": {
+            "type": "object",
+            "properties": {
+                "away_losses": {
+                    "type": "integer"
+                },
+                "away_wins": {
+                    "type": "integer"
+                },
+                "conference_id": {
+                    "type": "integer"
+                },
+                "division": {
+                    "type": "string"
+                },
+                "division_id": {
+                    "type": "integer"
+                },
+                "games_behind": {
+                    "description": "behind the conference leader",
                     "type": "number"
                 },
-                "avg_turnovers": {
+                "home_losses": {
+                    "type": "integer"
+                },
+                "home_wins": {
+                    "type": "integer"
+                },
+                "last_10": {
+                    "description": "e.g. \"7-3\"",
+                    "type": "string"
+                },
+                "losses": {
+                    "type": "integer"
+                },
+                "points_against": {
+                    "type": "integer"
+                },
+                "points_for": {
+                    "type": "integer"
+                },
+                "seed": {
+                    "type": "integer"
+                },
+                "streak": {
+                    "description": "e.g. \"W3\"",
+                    "type": "string"
+                },
+                "team_id": {
+                    "type": "integer"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "tiebreak": {
+                    "type": "string"
+                },
+                "win_pct": {
                     "type": "number"
                 },
-                "player_id": {
+                "wins": {
                     "type": "integer"
                 }
             }
         },
-        "models.GameStat": {
+        "models.SeedList": {
+            "type": "object",
+            "properties": {
+                "conferences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ConferenceSeeds"
+                    }
+                },
+                "season": {
+                    "$ref": "#/definitions/models.Season"
+                }
+            }
+        },
+        "models.Standings": {
+            "type": "object",
+            "properties": {
+                "conferences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ConferenceStandings"
+                    }
+                },
+                "season": {
+                    "$ref": "#/definitions/models.Season"
+                }
+            }
+        },
+        "models.StatDistribution": {
+            "type": "object",
+            "properties": {
+                "games_played": {
+                    "type": "integer"
+                },
+                "stats": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.Distribution"
+                    }
+                }
+            }
+        },
+        "models.Team": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "division_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "league_id": {
+                    "description": "taken from the division when omitted",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "players": {
+                    "description": "roster, only filled in when fetching a single team",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Player"
+                    }
+                }
+            }
+        },
+        "models.TeamGameStat": {
             "type": "object",
             "properties": {
                 "assists": {
@@ -258,44 +2931,151 @@ const docTemplate = `{
                 "blocks": {
                     "type": "integer"
                 },
+                "defensive_rebounds": {
+                    "type": "integer"
+                },
+                "field_goals_attempted": {
+                    "type": "integer"
+                },
+                "field_goals_made": {
+                    "type": "integer"
+                },
                 "fouls": {
                     "type": "integer"
                 },
-                "game_date": {
-                    "type": "string"
+                "free_throws_attempted": {
+                    "type": "integer"
+                },
+                "free_throws_made": {
+                    "type": "integer"
+                },
+                "game_id": {
+                    "type": "integer"
                 },
                 "minutes_played": {
                     "type": "number"
                 },
-                "player_id": {
+                "offensive_rebounds": {
+                    "type": "integer"
+                },
+                "plus_minus": {
                     "type": "integer"
                 },
                 "points": {
                     "type": "integer"
                 },
                 "rebounds": {
+                    "description": "must equal offensive + defensive; derived when omitted",
                     "type": "integer"
                 },
                 "steals": {
                     "type": "integer"
                 },
+                "team_id": {
+                    "type": "integer"
+                },
+                "team_rebounds": {
+                    "type": "integer"
+                },
+                "team_turnovers": {
+                    "type": "integer"
+                },
+                "three_pointers_attempted": {
+                    "type": "integer"
+                },
+                "three_pointers_made": {
+                    "type": "integer"
+                },
                 "turnovers": {
                     "type": "integer"
                 }
             }
         },
-        "models.Player": {
+        "models.TeamRecord": {
             "type": "object",
             "properties": {
-                "id": {
+                "away_losses": {
                     "type": "integer"
                 },
-                "name": {
+                "away_wins": {
+                    "type": "integer"
+                },
+                "conference_id": {
+                    "type": "integer"
+                },
+                "division": {
+                    "type": "string"
+                },
+                "division_id": {
+                    "type": "integer"
+                },
+                "games_behind": {
+                    "description": "behind the conference leader",
+                    "type": "number"
+                },
+                "home_losses": {
+                    "type": "integer"
+                },
+                "home_wins": {
+                    "type": "integer"
+                },
+                "last_10": {
+                    "description": "e.g. \"7-3\"",
+                    "type": "string"
+                },
+                "losses": {
+                    "type": "integer"
+                },
+                "points_against": {
+                    "type": "integer"
+                },
+                "points_for": {
+                    "type": "integer"
+                },
+                "streak": {
+                    "description": "e.g. \"W3\"",
                     "type": "string"
                 },
                 "team_id": {
-                    "description": "New field for foreign key",
                     "type": "integer"
+                },
+                "team_name": {
+                    "type": "string"
+                },
+                "win_pct": {
+                    "type": "number"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Transaction": {
+            "type": "object",
+            "properties": {
+                "effective_date": {
+                    "type": "string"
+                },
+                "from_team_id": {
+                    "description": "filled in from the player's open stint",
+                    "type": "integer"
+                },
+                "player_id": {
+                    "type": "integer"
+                },
+                "to_team_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ValidationError": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldError"
+                    }
                 }
             }
         }
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// GetPlayerAdvancedStatHandler godoc
+// @Summary player advanced stats
+// @Description Get a player's efficiency metrics: true shooting, effective field goal, usage, assist, rebound and turnover percentages and the average game score. Team context comes from the games the player played, using each team's recorded box score where there is one and the sum of its players' lines otherwise.
+// @Tags players
+// @Produce json
+// @Param playerId path int true "PlayerId"
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs"
+// @Param from query string false "First game date, YYYY-MM-DD"
+// @Param to query string false "Last game date, YYYY-MM-DD"
+// @Param last_n query int false "Only the last N games matching the other filters"
+// @Success 200 {object} models.AdvancedStat
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Player not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /stat/players/{playerId}/advanced [get]
+func GetPlayerAdvancedStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, err := strconv.Atoi(mux.Vars(r)["playerId"])
+		if err != nil {
+			http.Error(w, "Invalid player ID", http.StatusBadRequest)
+			return
+		}
+		filter, err := parseStatFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if filter.Period != "" {
+			http.Error(w, "period is not supported: team totals are kept per game", http.StatusBadRequest)
+			return
+		}
+		cacheKey := filter.cacheKey(fmt.Sprintf("player_advanced_%d", playerID))
+
+		// the metrics also depend on the totals of the player's teams and
+		// their opponents, so the value carries their tags too
+		data, err := cachedTagged(c, cacheKey, func() (interface{}, []string, error) {
+			stat, teamIDs, err := getAdvancedPlayerStats(db, playerID, filter)
+			if err != nil {
+				return nil, nil, err
+			}
+			tags := []string{playerTag(playerID)}
+			for _, id := range teamIDs {
+				tags = append(tags, teamTag(id))
+			}
+			return stat, filter.tags(tags...), nil
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Player not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+// teamContext sums the totals of a player's team and its opponents over the
+// games the player played
+type teamContext struct {
+	FieldGoalsMade      float64
+	FieldGoalsAttempted float64
+	FreeThrowsAttempted float64
+	Turnovers           float64
+	Rebounds            float64
+	MinutesPlayed       float64
+	OpponentRebounds    float64
+}
+
+// teamBoxColumns are the columns of teamBox
+var teamBoxColumns = []string{"points", "offensive_rebounds", "defensive_rebounds", "rebounds", "assists",
+	"turnovers", "field_goals_made", "field_goals_attempted", "free_throws_made", "free_throws_attempted", "minutes_played"}
+
+// teamBox returns a lateral subquery of a team's totals in a game, the game
+// and team being columns of the outer query. A recorded box score is used
+// where there is one, and the sum of the team's player lines otherwise.
+func teamBox(game, team string) string {
+	recorded := make([]string, len(teamBoxColumns))
+	summed := make([]string, len(teamBoxColumns))
+	for i, c := range teamBoxColumns {
+		recorded[i] = "COALESCE(team_stats." + c + ", lines." + c + ", 0) AS " + c
+		summed[i] = "SUM(s." + c + ") AS " + c
+	}
+	// a box score without minutes is taken to not have recorded them
+	recorded[len(recorded)-1] = "COALESCE(NULLIF(team_stats.minutes_played, 0), lines.minutes_played, 0) AS minutes_played"
+
+	return `LATERAL (
+		SELECT ` + strings.Join(recorded, ", ") + `
+		FROM (
+			SELECT ` + strings.Join(summed, ", ") + `
+			FROM stats s
+			JOIN stat_teams st ON st.stat_id = s.id
+			WHERE s.game_id = ` + game + ` AND st.team_id = ` + team + `
+		) AS lines
+		LEFT JOIN team_stats ON team_stats.game_id = ` + game + ` AND team_stats.team_id = ` + team + `
+	)`
+}
+
+// possessions estimates the possessions of the totals named by table
+func possessions(table string) string {
+	return table + `.field_goals_attempted + 0.44 * ` + table + `.free_throws_attempted - ` +
+		table + `.offensive_rebounds + ` + table + `.turnovers`
+}
+
+// gameLines selects the stat lines matching where that belong to a game,
+// with the team the player was on and its opponent
+func gameLines(where string) string {
+	return `SELECT stats.*, stat_teams.team_id,
+			CASE WHEN games.home_team_id = stat_teams.team_id THEN games.away_team_id ELSE games.home_team_id END AS opponent_id
+		FROM stats
+		JOIN stat_teams ON stat_teams.stat_id = stats.id
+		JOIN games ON games.id = stats.game_id
+		WHERE ` + where
+}
+
+// getAdvancedPlayerStats returns the player's advanced metrics and the ids of
+// the teams whose totals they were computed from
+func getAdvancedPlayerStats(db *sql.DB, playerID int, filter statFilter) (*models.AdvancedStat, []int, error) {
+	conds, args := filter.lastNWhere(` WHERE stats.player_id = $1`, []interface{}{playerID})
+	query := `
+		WITH player_lines AS (` + gameLines(`stats.player_id = $1`+conds) + `)
+		SELECT` + statTotalsColumns + `,
+			COALESCE(SUM(team.field_goals_made), 0),
+			COALESCE(SUM(team.field_goals_attempted), 0),
+			COALESCE(SUM(team.free_throws_attempted), 0),
+			COALESCE(SUM(team.turnovers), 0),
+			COALESCE(SUM(team.rebounds), 0),
+			COALESCE(SUM(team.minutes_played), 0),
+			COALESCE(SUM(opponent.rebounds), 0),
+			array_agg(DISTINCT stats.team_id) || array_agg(DISTINCT stats.opponent_id)
+		FROM player_lines AS stats
+		CROSS JOIN ` + teamBox("stats.game_id", "stats.team_id") + ` AS team
+		CROSS JOIN ` + teamBox("stats.game_id", "stats.opponent_id") + ` AS opponent`
+
+	var tm teamContext
+	var teamIDs []int64
+	totals, err := scanTotals(db.QueryRow(query, args...),
+		&tm.FieldGoalsMade, &tm.FieldGoalsAttempted, &tm.FreeThrowsAttempted, &tm.Turnovers,
+		&tm.Rebounds, &tm.MinutesPlayed, &tm.OpponentRebounds, pq.Array(&teamIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]int, len(teamIDs))
+	for i, id := range teamIDs {
+		ids[i] = int(id)
+	}
+	return totals.advanced(tm), ids, nil
+}
+
+// advanced derives the advanced metrics from a player's totals and team
+// context, following Basketball-Reference's definitions. A team's minutes
+// divided by 5 is the time it had on the floor, so the player's minutes
+// over that are the share of the team's games the player was on for.
+func (t statTotals) advanced(tm teamContext) *models.AdvancedStat {
+	teamFloorMinutes := tm.MinutesPlayed / 5
+	onFloor := ratio(t.MinutesPlayed, teamFloorMinutes)
+	possessions := t.FieldGoalsAttempted + 0.44*t.FreeThrowsAttempted + t.Turnovers
+	teamPossessions := tm.FieldGoalsAttempted + 0.44*tm.FreeThrowsAttempted + tm.Turnovers
+
+	teammateFieldGoals := onFloor*tm.FieldGoalsMade - t.FieldGoalsMade
+	if teammateFieldGoals < 0 {
+		teammateFieldGoals = 0
+	}
+
+	gameScore := t.Points + 0.4*t.FieldGoalsMade - 0.7*t.FieldGoalsAttempted - 0.4*(t.FreeThrowsAttempted-t.FreeThrowsMade) +
+		0.7*t.OffensiveRebounds + 0.3*t.DefensiveRebounds + t.Steals + 0.7*t.Assists + 0.7*t.Blocks - 0.4*t.Fouls - t.Turnovers
+
+	return &models.AdvancedStat{
+		GamesPlayed:           t.Games,
+		TrueShootingPct:       ratio(t.Points, 2*(t.FieldGoalsAttempted+0.44*t.FreeThrowsAttempted)),
+		EffectiveFieldGoalPct: ratio(t.FieldGoalsMade+0.5*t.ThreePointersMade, t.FieldGoalsAttempted),
+		UsagePct:              ratio(possessions*teamFloorMinutes, t.MinutesPlayed*teamPossessions),
+		AssistPct:             ratio(t.Assists, teammateFieldGoals),
+		ReboundPct:            ratio(t.Rebounds*teamFloorMinutes, t.MinutesPlayed*(tm.Rebounds+tm.OpponentRebounds)),
+		TurnoverPct:           ratio(t.Turnovers, possessions),
+		GameScore:             gameScore / float64(t.Games),
+	}
+}
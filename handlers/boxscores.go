@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// PutTeamGameStatHandler godoc
+// @Summary Record a team box score
+// @Description Create or replace a team's box score for a game. Totals include team rebounds and team turnovers, which are also given on their own.
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param gameId path int true "gameId"
+// @Param teamId path int true "teamId"
+// @Param stat body models.TeamGameStat true "Team box score"
+// @Success 200 {object} models.TeamGameStat
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Game not found"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /games/{gameId}/team-stats/{teamId} [put]
+func PutTeamGameStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		gameID, err := strconv.Atoi(vars["gameId"])
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusBadRequest)
+			return
+		}
+		teamID, err := strconv.Atoi(vars["teamId"])
+		if err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+
+		var stat models.TeamGameStat
+		if err := json.NewDecoder(r.Body).Decode(&stat); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stat.GameID, stat.TeamID = gameID, teamID
+		if stat.Rebounds == 0 {
+			stat.Rebounds = stat.OffensiveRebounds + stat.DefensiveRebounds
+		}
+
+		game, err := getGame(db, gameID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Game not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		rules, err := teamRules(db, game.HomeTeamID)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs := validateTeamGameStat(stat, game, rules); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		query := `INSERT INTO team_stats (game_id, team_id, points, rebounds, offensive_rebounds, defensive_rebounds, team_rebounds,
+                      assists, steals, blocks, fouls, turnovers, team_turnovers,
+                      field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+                      free_throws_made, free_throws_attempted, minutes_played, plus_minus)
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+                  ON CONFLICT (game_id, team_id) DO UPDATE SET
+                      points = EXCLUDED.points, rebounds = EXCLUDED.rebounds,
+                      offensive_rebounds = EXCLUDED.offensive_rebounds, defensive_rebounds = EXCLUDED.defensive_rebounds,
+                      team_rebounds = EXCLUDED.team_rebounds, assists = EXCLUDED.assists, steals = EXCLUDED.steals,
+                      blocks = EXCLUDED.blocks, fouls = EXCLUDED.fouls, turnovers = EXCLUDED.turnovers,
+                      team_turnovers = EXCLUDED.team_turnovers,
+                      field_goals_made = EXCLUDED.field_goals_made, field_goals_attempted = EXCLUDED.field_goals_attempted,
+                      three_pointers_made = EXCLUDED.three_pointers_made, three_pointers_attempted = EXCLUDED.three_pointers_attempted,
+                      free_throws_made = EXCLUDED.free_throws_made, free_throws_attempted = EXCLUDED.free_throws_attempted,
+                      minutes_played = EXCLUDED.minutes_played, plus_minus = EXCLUDED.plus_minus`
+		_, err = db.Exec(query, stat.GameID, stat.TeamID, stat.Points, stat.Rebounds, stat.OffensiveRebounds, stat.DefensiveRebounds,
+			stat.TeamRebounds, stat.Assists, stat.Steals, stat.Blocks, stat.Fouls, stat.Turnovers, stat.TeamTurnovers,
+			stat.FieldGoalsMade, stat.FieldGoalsAttempted, stat.ThreePointersMade, stat.ThreePointersAttempted,
+			stat.FreeThrowsMade, stat.FreeThrowsAttempted, stat.MinutesPlayed, stat.PlusMinus)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// the team's box scores are the team context of its players'
+		// advanced metrics, and those of its opponents
+		if err := c.Invalidate(teamTag(teamID)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stat)
+	}
+}
+
+// ListTeamGameStatsHandler godoc
+// @Summary Team box scores of a game
+// @Description Get the team box scores recorded for a game
+// @Tags games
+// @Produce json
+// @Param gameId path int true "gameId"
+// @Success 200 {array} models.TeamGameStat
+// @Failure 400 {string} string "Invalid game ID"
+// @Failure 500 {string} string "Internal server error"
+// @Router /games/{gameId}/team-stats [get]
+func ListTeamGameStatsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID, err := strconv.Atoi(mux.Vars(r)["gameId"])
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusBadRequest)
+			return
+		}
+
+		rows, err := db.Query(`
+			SELECT game_id, team_id, points, rebounds, offensive_rebounds, defensive_rebounds, team_rebounds,
+				assists, steals, blocks, fouls, turnovers, team_turnovers,
+				field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+				free_throws_made, free_throws_attempted, minutes_played, plus_minus
+			FROM team_stats WHERE game_id = $1 ORDER BY team_id`, gameID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		stats := []models.TeamGameStat{}
+		for rows.Next() {
+			var s models.TeamGameStat
+			err := rows.Scan(&s.GameID, &s.TeamID, &s.Points, &s.Rebounds, &s.OffensiveRebounds, &s.DefensiveRebounds, &s.TeamRebounds,
+				&s.Assists, &s.Steals, &s.Blocks, &s.Fouls, &s.Turnovers, &s.TeamTurnovers,
+				&s.FieldGoalsMade, &s.FieldGoalsAttempted, &s.ThreePointersMade, &s.ThreePointersAttempted,
+				&s.FreeThrowsMade, &s.FreeThrowsAttempted, &s.MinutesPlayed, &s.PlusMinus)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			stats = append(stats, s)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	}
+}
+
+// ReconciliationReportHandler godoc
+// @Summary Score reconciliation report
+// @Description Check every final game against its lines: the points of each team's players, credited by the team they played for on the game date, and the team box score where one is recorded, must both equal the team's final score. Lists the sides that do not.
+// @Tags reports
+// @Produce json
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs"
+// @Param from query string false "First game date, YYYY-MM-DD"
+// @Param to query string false "Last game date, YYYY-MM-DD"
+// @Success 200 {object} models.DataQualityReport
+// @Failure 400 {string} string "Bad request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /reports/reconciliation [get]
+func ReconciliationReportHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseStatFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if filter.Period != "" || filter.LastN != 0 {
+			http.Error(w, "period and last_n are not supported: the report covers whole games by date", http.StatusBadRequest)
+			return
+		}
+		conds, args := filter.dateWhere("games.game_date", nil)
+
+		rows, err := db.Query(`
+			SELECT games.id, games.game_date, sides.team_id, sides.score,
+				COALESCE(players.points, 0), COALESCE(players.lines, 0), team_stats.points
+			FROM games
+			CROSS JOIN LATERAL (VALUES (games.home_team_id, games.home_score), (games.away_team_id, games.away_score))
+				AS sides (team_id, score)
+			LEFT JOIN LATERAL (
+				SELECT SUM(stats.points) AS points, COUNT(*) AS lines
+				FROM stats
+				JOIN stat_teams ON stat_teams.stat_id = stats.id
+				WHERE stats.game_id = games.id AND stat_teams.team_id = sides.team_id
+			) AS players ON true
+			LEFT JOIN team_stats ON team_stats.game_id = games.id AND team_stats.team_id = sides.team_id
+			WHERE games.status = 'final'`+conds+`
+			ORDER BY games.game_date, games.id, sides.team_id`, args...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		report := models.DataQualityReport{Issues: []models.ReconciliationIssue{}}
+		games := map[int]bool{}
+		for rows.Next() {
+			var side models.ReconciliationIssue
+			var playerPoints int
+			var teamPoints sql.NullInt64
+			err := rows.Scan(&side.GameID, &side.GameDate, &side.TeamID, &side.FinalScore,
+				&playerPoints, &side.PlayerLines, &teamPoints)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			games[side.GameID] = true
+
+			if playerPoints != side.FinalScore {
+				issue := side
+				issue.Check, issue.Points = "player_points", playerPoints
+				report.Issues = append(report.Issues, issue)
+			}
+			if teamPoints.Valid && int(teamPoints.Int64) != side.FinalScore {
+				issue := side
+				issue.Check, issue.Points = "team_points", int(teamPoints.Int64)
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report.GamesChecked = len(games)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"nba_stats/cache"
+	"time"
+)
+
+// Cached aggregates are tagged with the players, teams and seasons they are
+// computed from, and every write invalidates the tags it touches. No
+// aggregate is keyed by a single game, so writes to a game invalidate the
+// players and teams with lines in it instead.
+
+const cacheTTL = 24 * time.Hour
+
+// standingsTag is carried by everything derived from game results
+const standingsTag = "standings"
+
+// seasonsTag is carried by aggregates filtered by season type alone, which
+// depend on every season of that type
+const seasonsTag = "seasons"
+
+func playerTag(playerID int) string { return fmt.Sprintf("player:%d", playerID) }
+
+func teamTag(teamID int) string { return fmt.Sprintf("team:%d", teamID) }
+
+func seasonTag(year int) string { return fmt.Sprintf("season:%d", year) }
+
+// flights coalesces the computations of a key, so that when a popular key
+// expires one request recomputes it while the others wait for its result
+var flights cache.Group
+
+// staleFor is how long past its TTL a value is still served while it is
+// recomputed in the background. Zero disables stale-while-revalidate.
+var staleFor time.Duration
+
+// ServeStale turns on stale-while-revalidate: for d after a cached value
+// expires it is still served, and the first request to see it expired
+// recomputes it in the background. Values dropped by a write are never
+// served stale. Call it before serving requests.
+func ServeStale(d time.Duration) {
+	staleFor = d
+}
+
+// freshKey marks a key as fresh for cacheTTL, while the key itself is kept
+// for another staleFor
+func freshKey(key string) string { return key + ":fresh" }
+
+// cached returns the JSON cached under key. On a miss it computes the value,
+// caches it with its tags and returns it; concurrent misses on the same key
+// share one computation. A cache that cannot be reached is logged and
+// bypassed, so the value is computed from Postgres.
+func cached(c cache.Cache, key string, tags []string, compute func() (interface{}, error)) ([]byte, error) {
+	return cachedTagged(c, key, func() (interface{}, []string, error) {
+		v, err := compute()
+		return v, tags, err
+	})
+}
+
+// cachedTagged is cached for values whose tags are only known once they are
+// computed
+func cachedTagged(c cache.Cache, key string, compute func() (interface{}, []string, error)) ([]byte, error) {
+	refresh := func() ([]byte, error) {
+		return store(c, key, compute)
+	}
+
+	data, err := c.Get(key)
+	if err == nil {
+		if staleFor > 0 && isStale(c, key) {
+			flights.Go(key, func() ([]byte, error) {
+				data, err := refresh()
+				if err != nil {
+					log.Printf("cache refresh %s: %v\n", key, err)
+				}
+				return data, err
+			})
+		}
+		return data, nil
+	}
+	if err != cache.ErrMiss {
+		log.Printf("cache get %s: %v\n", key, err)
+	}
+	return flights.Do(key, refresh)
+}
+
+// isStale reports whether the value cached under key has outlived cacheTTL.
+// A marker that cannot be read counts as fresh, so an unreachable cache does
+// not trigger refreshes.
+func isStale(c cache.Cache, key string) bool {
+	_, err := c.Get(freshKey(key))
+	if err != nil && err != cache.ErrMiss {
+		log.Printf("cache get %s: %v\n", freshKey(key), err)
+	}
+	return err == cache.ErrMiss
+}
+
+// store computes the value of key and caches its JSON. A write that commits
+// while the value is computed may not be reflected in it, so the value is
+// only cached if none of its tags was invalidated meanwhile.
+func store(c cache.Cache, key string, compute func() (interface{}, []string, error)) ([]byte, error) {
+	mark, markErr := c.Mark()
+	if markErr != nil {
+		log.Printf("cache mark %s: %v\n", key, markErr)
+	}
+	v, tags, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil || markErr != nil {
+		return data, err
+	}
+	if err := c.Set(mark, key, data, cacheTTL+staleFor, tags...); err != nil {
+		log.Printf("cache set %s: %v\n", key, err)
+		return data, nil
+	}
+	if staleFor > 0 {
+		// the marker carries the key's tags, so a write drops both
+		if err := c.Set(mark, freshKey(key), []byte{}, cacheTTL, tags...); err != nil {
+			log.Printf("cache set %s: %v\n", freshKey(key), err)
+		}
+	}
+	return data, nil
+}
+
+// tags returns the tags of an aggregate over the filter's window, given the
+// tags of what it aggregates
+func (f statFilter) tags(tags ...string) []string {
+	if f.Season != 0 {
+		return append(tags, seasonTag(f.Season))
+	}
+	if f.SeasonType != "" {
+		return append(tags, seasonsTag)
+	}
+	return tags
+}
+
+// playerRecordTags returns the tags of what embeds a player's record rather
+// than their stat lines: the player's own aggregates and the PER of the
+// seasons the player has a rating in, which carries the player's name
+func playerRecordTags(db *sql.DB, playerID int) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT seasons.year
+		FROM player_efficiency
+		JOIN seasons ON seasons.id = player_efficiency.season_id
+		WHERE player_efficiency.player_id = $1`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{playerTag(playerID)}
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, err
+		}
+		tags = append(tags, seasonTag(year))
+	}
+	return tags, rows.Err()
+}
+
+// gameTags returns the tags of the players with lines in a game and of the
+// teams they played for
+func gameTags(db *sql.DB, gameID int) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT stats.player_id, COALESCE(stat_teams.team_id, 0)
+		FROM stats
+		LEFT JOIN stat_teams ON stat_teams.stat_id = stats.id
+		WHERE stats.game_id = $1`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var playerID, teamID int
+		if err := rows.Scan(&playerID, &teamID); err != nil {
+			return nil, err
+		}
+		tags = append(tags, playerTag(playerID))
+		if teamID != 0 {
+			tags = append(tags, teamTag(teamID))
+		}
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+)
+
+// defaultPercentiles are reported when the request does not name any
+var defaultPercentiles = []float64{10, 25, 75, 90}
+
+// maxPercentiles bounds the percentiles one request can ask for
+const maxPercentiles = 20
+
+// GetPlayerDistributionHandler godoc
+// @Summary player stat distribution
+// @Description Get the sum, count, median, sample standard deviation, min, max and percentiles of every box score column over a player's stat lines, to show how consistent the player is
+// @Tags players
+// @Produce json
+// @Param playerId path int true "PlayerId"
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs"
+// @Param period query string false "Period number (1-4 quarters, 5+ overtimes) or ot for all overtimes"
+// @Param from query string false "First game date, YYYY-MM-DD"
+// @Param to query string false "Last game date, YYYY-MM-DD"
+// @Param last_n query int false "Only the last N games matching the other filters"
+// @Param percentiles query string false "Comma-separated percentiles between 0 and 100, by default 10,25,75,90"
+// @Success 200 {object} models.StatDistribution
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Player not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /stat/players/{playerId}/distribution [get]
+func GetPlayerDistributionHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, err := strconv.Atoi(mux.Vars(r)["playerId"])
+		if err != nil {
+			http.Error(w, "Invalid player ID", http.StatusBadRequest)
+			return
+		}
+		filter, err := parseStatFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		percentiles, err := parsePercentiles(r.URL.Query().Get("percentiles"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cacheKey := filter.cacheKey(fmt.Sprintf("player_distribution_%d", playerID)) +
+			":percentiles=" + strings.Join(percentileKeys(percentiles), ",")
+
+		data, err := cached(c, cacheKey, filter.tags(playerTag(playerID)), func() (interface{}, error) {
+			return getPlayerDistribution(db, playerID, filter, percentiles)
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Player not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+// parsePercentiles parses a comma-separated list of percentiles, returning
+// them sorted and without duplicates so that equivalent lists share a cache
+// entry
+func parsePercentiles(v string) ([]float64, error) {
+	if v == "" {
+		return defaultPercentiles, nil
+	}
+	seen := map[float64]bool{}
+	var percentiles []float64
+	for _, part := range strings.Split(v, ",") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || math.IsNaN(p) || p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q", part)
+		}
+		if !seen[p] {
+			seen[p] = true
+			percentiles = append(percentiles, p)
+		}
+	}
+	if len(percentiles) > maxPercentiles {
+		return nil, fmt.Errorf("at most %d percentiles can be asked for", maxPercentiles)
+	}
+	sort.Float64s(percentiles)
+	return percentiles, nil
+}
+
+// percentileKeys names percentiles in responses and cache keys, e.g. p90
+func percentileKeys(percentiles []float64) []string {
+	keys := make([]string, len(percentiles))
+	for i, p := range percentiles {
+		keys[i] = "p" + strconv.FormatFloat(p, 'f', -1, 64)
+	}
+	return keys
+}
+
+func getPlayerDistribution(db *sql.DB, playerID int, filter statFilter, percentiles []float64) (*models.StatDistribution, error) {
+	fractions := make([]float64, len(percentiles))
+	for i, p := range percentiles {
+		fractions[i] = p / 100
+	}
+	conds, args := filter.lastNWhere(` WHERE stats.player_id = $1`, []interface{}{playerID, pq.Array(fractions)})
+
+	columns := boxColumns(models.BoxScore{})
+	selects := []string{"COUNT(*)"}
+	for _, c := range columns {
+		col := "stats." + c.field
+		selects = append(selects,
+			"COALESCE(SUM("+col+"), 0)",
+			"COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY "+col+"), 0)",
+			"COALESCE(stddev_samp("+col+"), 0)",
+			"COALESCE(MIN("+col+"), 0)",
+			"COALESCE(MAX("+col+"), 0)",
+			"percentile_cont($2::float8[]) WITHIN GROUP (ORDER BY "+col+")")
+	}
+	query := `
+		SELECT ` + strings.Join(selects, ",\n\t\t\t") + `
+		FROM
+			` + filter.from() + `
+		WHERE
+			stats.player_id = $1` + conds
+
+	var count int
+	dists := make([]models.Distribution, len(columns))
+	values := make([][]float64, len(columns))
+	dest := []interface{}{&count}
+	for i := range columns {
+		d := &dists[i]
+		dest = append(dest, &d.Sum, &d.Median, &d.StdDev, &d.Min, &d.Max, pq.Array(&values[i]))
+	}
+	if err := db.QueryRow(query, args...).Scan(dest...); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	keys := percentileKeys(percentiles)
+	result := &models.StatDistribution{GamesPlayed: count, Stats: map[string]models.Distribution{}}
+	for i, c := range columns {
+		d := dists[i]
+		d.Count = count
+		d.Percentiles = map[string]float64{}
+		for j, key := range keys {
+			if j < len(values[i]) {
+				d.Percentiles[key] = values[i][j]
+			}
+		}
+		result.Stats[c.field] = d
+	}
+	return result, nil
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// externalColumns maps the entities an external id can name to their column
+// in external_ids
+var externalColumns = map[string]string{
+	"players": "player_id",
+	"teams":   "team_id",
+	"games":   "game_id",
+}
+
+// GetExternalIDHandler godoc
+// @Summary Resolve an external id
+// @Description Get the record a provider's id is mapped to
+// @Tags external ids
+// @Produce json
+// @Param source path string true "Provider name"
+// @Param entity path string true "players, teams or games"
+// @Param externalId path string true "The provider's id"
+// @Success 200 {object} models.ExternalID
+// @Failure 404 {string} string "External id not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /sources/{source}/{entity}/{externalId} [get]
+func GetExternalIDHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if _, ok := externalColumns[vars["entity"]]; !ok {
+			http.Error(w, "External id not found", http.StatusNotFound)
+			return
+		}
+		ext := models.ExternalID{Source: vars["source"], Entity: vars["entity"], ExternalID: vars["externalId"]}
+
+		id, err := lookupExternal(db, ext.Entity, ext.Source, ext.ExternalID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "External id not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		ext.InternalID = id
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ext)
+	}
+}
+
+// UpsertPlayerHandler godoc
+// @Summary Upsert a player by external id
+// @Description Create or update the player a provider's id is mapped to. An unmapped id creates a player, unless the body gives the id of an existing player, which links the two so that feeds from different providers converge on one record. An existing player's team is only changed through transactions.
+// @Tags external ids
+// @Accept json
+// @Produce json
+// @Param source path string true "Provider name"
+// @Param externalId path string true "The provider's id"
+// @Param player body models.Player true "Player"
+// @Success 200 {object} models.Player
+// @Success 201 {object} models.Player
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /sources/{source}/players/{externalId} [put]
+func UpsertPlayerHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var player models.Player
+		if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validatePlayer(player)
+		refErrs, err := checkPlayerRefs(db, player)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		created, errs, err := upsertExternal(tx, "players", vars["source"], vars["externalId"], &player.ID,
+			func() error { return insertPlayer(tx, &player) },
+			func() (bool, error) { return updatePlayer(tx, &player) })
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		tags, err := playerRecordTags(db, player.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := c.Invalidate(tags...); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeUpserted(w, created, player)
+	}
+}
+
+// UpsertTeamHandler godoc
+// @Summary Upsert a team by external id
+// @Description Create or update the team a provider's id is mapped to. An unmapped id creates a team, unless the body gives the id of an existing team, which links the two.
+// @Tags external ids
+// @Accept json
+// @Produce json
+// @Param source path string true "Provider name"
+// @Param externalId path string true "The provider's id"
+// @Param team body models.Team true "Team"
+// @Success 200 {object} models.Team
+// @Success 201 {object} models.Team
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /sources/{source}/teams/{externalId} [put]
+func UpsertTeamHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var team models.Team
+		if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validateTeam(team)
+		leagueErrs, err := resolveLeague(db, &team)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, leagueErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		team.Players = nil
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		created, errs, err := upsertExternal(tx, "teams", vars["source"], vars["externalId"], &team.ID,
+			func() error { return insertTeam(tx, &team) },
+			func() (bool, error) { return updateTeam(tx, team) })
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// a new team has a row in the standings, and an existing one may have
+		// moved division or league
+		if err := c.Invalidate(standingsTag, teamTag(team.ID)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeUpserted(w, created, team)
+	}
+}
+
+// UpsertGameHandler godoc
+// @Summary Upsert a game by external id
+// @Description Create or update the game a provider's id is mapped to. An unmapped id creates a game, unless the body gives the id of an existing game, which links the two.
+// @Tags external ids
+// @Accept json
+// @Produce json
+// @Param source path string true "Provider name"
+// @Param externalId path string true "The provider's id"
+// @Param game body models.Game true "Game"
+// @Success 200 {object} models.Game
+// @Success 201 {object} models.Game
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /sources/{source}/games/{externalId} [put]
+func UpsertGameHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		var game models.Game
+		if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if game.Status == "" {
+			game.Status = "scheduled"
+		}
+		errs := validateGame(game)
+		refErrs, err := checkGameRefs(db, game)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		var oldStatus string
+		var tags []string
+		var lineErrs fieldErrors
+		created, errs, err := upsertExternal(tx, "games", vars["source"], vars["externalId"], &game.ID,
+			func() error { return insertGame(tx, &game) },
+			func() (bool, error) {
+				old, err := getGame(tx, game.ID)
+				if err == sql.ErrNoRows {
+					return false, nil
+				} else if err != nil {
+					return false, err
+				}
+				oldStatus = old.Status
+				if tags, err = gameTags(db, game.ID); err != nil {
+					return false, err
+				}
+				rules, err := teamRules(db, game.HomeTeamID)
+				if err != nil {
+					return false, err
+				}
+				// the game is left as it is and the request rejected below
+				if lineErrs, err = checkGameLines(tx, *old, game, rules); err != nil || len(lineErrs) > 0 {
+					return true, err
+				}
+				return updateGame(tx, game)
+			})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, lineErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := invalidateGameUpdate(db, c, game, oldStatus, tags); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeUpserted(w, created, game)
+	}
+}
+
+// upsertExternal finds the record source's externalID is mapped to and
+// updates it, or inserts a record and maps the id to it. *id is the internal
+// id given in the request: it links an unmapped external id to an existing
+// record instead of inserting one, and is set to the id of the record
+// written. update reports whether the record was found.
+//
+// A concurrent upsert may map the same external id between the lookup and
+// the link. The record inserted here is then rolled back and the one mapped
+// meanwhile is updated instead, so both requests converge on one record.
+func upsertExternal(tx *sql.Tx, entity, source, externalID string, id *int,
+	insert func() error, update func() (bool, error)) (created bool, errs fieldErrors, err error) {
+	mapped, err := lookupExternal(tx, entity, source, externalID)
+	switch {
+	case err == nil:
+		if *id != 0 && *id != mapped {
+			errs.add("id", "external_id", "%s id %s is already mapped to %d", source, externalID, mapped)
+			return false, errs, nil
+		}
+		*id = mapped
+		_, err = update()
+		return false, nil, err
+	case err != sql.ErrNoRows:
+		return false, nil, err
+	case *id == 0:
+		if _, err := tx.Exec(`SAVEPOINT upsert_external`); err != nil {
+			return false, nil, err
+		}
+		if err := insert(); err != nil {
+			return false, nil, err
+		}
+		linked, err := linkExternal(tx, entity, source, externalID, *id)
+		if err != nil || linked {
+			return linked, nil, err
+		}
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT upsert_external`); err != nil {
+			return false, nil, err
+		}
+		if *id, err = lookupExternal(tx, entity, source, externalID); err != nil {
+			return false, nil, err
+		}
+		_, err = update()
+		return false, nil, err
+	default:
+		found, err := update()
+		if err != nil {
+			return false, nil, err
+		}
+		if !found {
+			errs.add("id", "exists", "%s %d does not exist", entity, *id)
+			return false, errs, nil
+		}
+		linked, err := linkExternal(tx, entity, source, externalID, *id)
+		if isUniqueViolation(err) {
+			errs.add("id", "external_id", "%s %d is already mapped to another %s id", entity, *id, source)
+			return false, errs, nil
+		}
+		if err != nil || linked {
+			return false, nil, err
+		}
+		// mapped meanwhile: the request only stands if to the same record
+		if mapped, err = lookupExternal(tx, entity, source, externalID); err != nil {
+			return false, nil, err
+		}
+		if mapped != *id {
+			errs.add("id", "external_id", "%s id %s is already mapped to %d", source, externalID, mapped)
+			return false, errs, nil
+		}
+		return false, nil, nil
+	}
+}
+
+// lookupExternal returns the internal id source's externalID is mapped to,
+// or sql.ErrNoRows
+func lookupExternal(ex execer, entity, source, externalID string) (int, error) {
+	column := externalColumns[entity]
+	var id int
+	query := `SELECT ` + column + ` FROM external_ids WHERE source = $1 AND external_id = $2 AND ` + column + ` IS NOT NULL`
+	err := ex.QueryRow(query, source, externalID).Scan(&id)
+	return id, err
+}
+
+// linkExternal maps source's externalID to id. linked is false if the
+// external id is already mapped, waiting for a concurrent mapping to commit.
+func linkExternal(ex execer, entity, source, externalID string, id int) (linked bool, err error) {
+	column := externalColumns[entity]
+	query := `INSERT INTO external_ids (source, external_id, ` + column + `) VALUES ($1, $2, $3)
+		ON CONFLICT (source, external_id) WHERE ` + column + ` IS NOT NULL DO NOTHING`
+	res, err := ex.Exec(query, source, externalID, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// writeUpserted responds 201 for a created record and 200 for an updated one
+func writeUpserted(w http.ResponseWriter, created bool, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statFilter narrows the stat lines an average is computed over. The zero
+// value matches every row.
+type statFilter struct {
+	Season     int
+	SeasonType string
+	// Period restricts the stats to one period ("1", "5", ...) or to all
+	// overtimes ("ot"), using the per-period rows of the stat lines. Where
+	// overtime starts depends on the rules of the game's league.
+	Period string
+	// From and To bound the game dates, both inclusive; zero is unbounded
+	From time.Time
+	To   time.Time
+	// LastN restricts the stats to the last N games matching the rest of
+	// the filter; see lastNWhere
+	LastN int
+}
+
+// dateLayout is the format of the from and to parameters
+const dateLayout = "2006-01-02"
+
+func parseStatFilter(r *http.Request) (statFilter, error) {
+	var f statFilter
+	q := r.URL.Query()
+	if v := q.Get("season"); v != "" {
+		season, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid season %q", v)
+		}
+		f.Season = season
+	}
+	if v := q.Get("season_type"); v != "" {
+		if !seasonTypes[v] {
+			return f, fmt.Errorf("invalid season_type %q", v)
+		}
+		f.SeasonType = v
+	}
+	if v := q.Get("period"); v != "" {
+		if v != "ot" {
+			period, err := strconv.Atoi(v)
+			if err != nil || period < 1 {
+				return f, fmt.Errorf("invalid period %q", v)
+			}
+			v = strconv.Itoa(period)
+		}
+		f.Period = v
+	}
+	for _, d := range []struct {
+		param string
+		date  *time.Time
+	}{{"from", &f.From}, {"to", &f.To}} {
+		if v := q.Get(d.param); v != "" {
+			date, err := time.Parse(dateLayout, v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD", d.param, v)
+			}
+			*d.date = date
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && f.From.After(f.To) {
+		return f, fmt.Errorf("from %s is after to %s", f.From.Format(dateLayout), f.To.Format(dateLayout))
+	}
+	if v := q.Get("last_n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return f, fmt.Errorf("invalid last_n %q", v)
+		}
+		f.LastN = n
+	}
+	return f, nil
+}
+
+// cacheKey appends the filter dimensions to base so that every window gets its
+// own cache entry. The unfiltered key is base itself.
+func (f statFilter) cacheKey(base string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	if f.Season != 0 {
+		fmt.Fprintf(&b, ":season=%d", f.Season)
+	}
+	if f.SeasonType != "" {
+		fmt.Fprintf(&b, ":season_type=%s", f.SeasonType)
+	}
+	if f.Period != "" {
+		fmt.Fprintf(&b, ":period=%s", f.Period)
+	}
+	if !f.From.IsZero() {
+		fmt.Fprintf(&b, ":from=%s", f.From.Format(dateLayout))
+	}
+	if !f.To.IsZero() {
+		fmt.Fprintf(&b, ":to=%s", f.To.Format(dateLayout))
+	}
+	if f.LastN != 0 {
+		fmt.Fprintf(&b, ":last_n=%d", f.LastN)
+	}
+	return b.String()
+}
+
+// from returns the relation the stat lines are read from, aliased as stats.
+// With a period filter each stat line is replaced by the sum of its matching
+// period rows, so a game only counts if the player has a row for the period.
+func (f statFilter) from() string {
+	if f.Period == "" {
+		return "stats"
+	}
+	cond := "stat_periods.period = " + f.Period
+	if f.Period == "ot" {
+		cond = `stat_periods.period > COALESCE((SELECT team_rules.periods FROM games
+			JOIN team_rules ON team_rules.team_id = games.home_team_id
+			WHERE games.id = stats.game_id), 4)`
+	}
+	return `(SELECT
+		stats.id, stats.player_id, stats.game_id, stats.game_date, stats.started,
+		SUM(stat_periods.points) AS points,
+		SUM(stat_periods.rebounds) AS rebounds,
+		SUM(stat_periods.offensive_rebounds) AS offensive_rebounds,
+		SUM(stat_periods.defensive_rebounds) AS defensive_rebounds,
+		SUM(stat_periods.assists) AS assists,
+		SUM(stat_periods.steals) AS steals,
+		SUM(stat_periods.blocks) AS blocks,
+		SUM(stat_periods.fouls) AS fouls,
+		SUM(stat_periods.turnovers) AS turnovers,
+		SUM(stat_periods.field_goals_made) AS field_goals_made,
+		SUM(stat_periods.field_goals_attempted) AS field_goals_attempted,
+		SUM(stat_periods.three_pointers_made) AS three_pointers_made,
+		SUM(stat_periods.three_pointers_attempted) AS three_pointers_attempted,
+		SUM(stat_periods.free_throws_made) AS free_throws_made,
+		SUM(stat_periods.free_throws_attempted) AS free_throws_attempted,
+		SUM(stat_periods.minutes_played) AS minutes_played,
+		SUM(stat_periods.plus_minus) AS plus_minus
+	FROM stats
+	JOIN stat_periods ON stat_periods.stat_id = stats.id
+	WHERE ` + cond + `
+	GROUP BY stats.id) AS stats`
+}
+
+// rollup reports whether the filter can be answered from stat_rollups, which
+// sum whole stat lines per player, team and season
+func (f statFilter) rollup() bool {
+	return f.Period == "" && f.From.IsZero() && f.To.IsZero() && f.LastN == 0
+}
+
+// rollupWhere is where for stat_rollups
+func (f statFilter) rollupWhere(args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	if f.Season != 0 || f.SeasonType != "" {
+		b.WriteString(" AND stat_rollups.season_id IN (SELECT seasons.id FROM seasons WHERE TRUE")
+		if f.Season != 0 {
+			args = append(args, f.Season)
+			fmt.Fprintf(&b, " AND seasons.year = $%d", len(args))
+		}
+		if f.SeasonType != "" {
+			args = append(args, f.SeasonType)
+			fmt.Fprintf(&b, " AND seasons.season_type = $%d", len(args))
+		}
+		b.WriteString(")")
+	}
+	return b.String(), args
+}
+
+// where returns the conditions on the stats table for the filter, each
+// prefixed with AND, and args extended with their placeholder values. LastN
+// is left to lastNWhere.
+func (f statFilter) where(args []interface{}) (string, []interface{}) {
+	return f.dateWhere("stats.game_date", args)
+}
+
+// lastNWhere is where including LastN, which keeps the stat lines dated on
+// the last N game dates among those matching the rest of the filter. lines
+// follows the filter's relation to select the stat lines of the player or
+// team averaged, such as " WHERE stats.player_id = $1", so that with a period
+// the dates are those of the last N games with a row for the period. A team
+// plays once a day, so its last N dates are its last N games.
+func (f statFilter) lastNWhere(lines string, args []interface{}) (string, []interface{}) {
+	conds, args := f.where(args)
+	if f.LastN == 0 {
+		return conds, args
+	}
+	args = append(args, f.LastN)
+	return conds + fmt.Sprintf(` AND stats.game_date IN (
+		SELECT DISTINCT stats.game_date FROM %s%s%s
+		ORDER BY stats.game_date DESC LIMIT $%d)`, f.from(), lines, conds, len(args)), args
+}
+
+// dateWhere is where for any relation with a game date, named by column
+func (f statFilter) dateWhere(column string, args []interface{}) (string, []interface{}) {
+	var b strings.Builder
+	if f.Season != 0 || f.SeasonType != "" {
+		b.WriteString(" AND EXISTS (SELECT 1 FROM seasons WHERE " + column + " BETWEEN seasons.start_date AND seasons.end_date")
+		if f.Season != 0 {
+			args = append(args, f.Season)
+			fmt.Fprintf(&b, " AND seasons.year = $%d", len(args))
+		}
+		if f.SeasonType != "" {
+			args = append(args, f.SeasonType)
+			fmt.Fprintf(&b, " AND seasons.season_type = $%d", len(args))
+		}
+		b.WriteString(")")
+	}
+	if !f.From.IsZero() {
+		args = append(args, f.From)
+		fmt.Fprintf(&b, " AND %s >= $%d", column, len(args))
+	}
+	if !f.To.IsZero() {
+		args = append(args, f.To)
+		fmt.Fprintf(&b, " AND %s <= $%d", column, len(args))
+	}
+	return b.String(), args
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AddGameHandler godoc
+// @Summary Add a new game
+// @Description Add a new game between two teams
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param game body models.Game true "Game"
+// @Success 201 {object} models.Game
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /games [post]
+func AddGameHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var game models.Game
+		if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if game.Status == "" {
+			game.Status = "scheduled"
+		}
+		errs := validateGame(game)
+		refErrs, err := checkGameRefs(db, game)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		if err := insertGame(db, &game); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if game.Status == "final" {
+			if err := c.Invalidate(standingsTag); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(game)
+	}
+}
+
+// ListGamesHandler godoc
+// @Summary List all games
+// @Description Get a list of all games ordered by date
+// @Tags games
+// @Produce json
+// @Success 200 {array} models.Game
+// @Failure 500 {string} string "Internal server error"
+// @Router /games [get]
+func ListGamesHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.Query(`SELECT id, home_team_id, away_team_id, game_date, home_score, away_score, status, overtime_periods
+                               FROM games ORDER BY game_date, id`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		games := []models.Game{}
+		for rows.Next() {
+			var game models.Game
+			if err := rows.Scan(&game.ID, &game.HomeTeamID, &game.AwayTeamID, &game.GameDate,
+				&game.HomeScore, &game.AwayScore, &game.Status, &game.OvertimePeriods); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			games = append(games, game)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(games)
+	}
+}
+
+// GetGameHandler godoc
+// @Summary Get a game
+// @Description Get a single game by id
+// @Tags games
+// @Produce json
+// @Param gameId path int true "gameId"
+// @Success 200 {object} models.Game
+// @Failure 400 {string} string "Invalid game ID"
+// @Failure 404 {string} string "Game not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /games/{gameId} [get]
+func GetGameHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID, err := strconv.Atoi(mux.Vars(r)["gameId"])
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusBadRequest)
+			return
+		}
+
+		game, err := getGame(db, gameID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Game not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(game)
+	}
+}
+
+// UpdateGameHandler godoc
+// @Summary Update a game
+// @Description Replace the teams, date, score and status of a game
+// @Tags games
+// @Accept json
+// @Produce json
+// @Param gameId path int true "gameId"
+// @Param game body models.Game true "Game"
+// @Success 200 {object} models.Game
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Game not found"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /games/{gameId} [put]
+func UpdateGameHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID, err := strconv.Atoi(mux.Vars(r)["gameId"])
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusBadRequest)
+			return
+		}
+
+		var game models.Game
+		if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		game.ID = gameID
+		if game.Status == "" {
+			game.Status = "scheduled"
+		}
+		errs := validateGame(game)
+		refErrs, err := checkGameRefs(db, game)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		old, err := getGame(db, gameID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Game not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		tags, err := gameTags(db, gameID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		rules, err := teamRules(db, game.HomeTeamID)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		lineErrs, err := checkGameLines(tx, *old, game, rules)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if len(lineErrs) > 0 {
+			writeValidationErrors(w, lineErrs)
+			return
+		}
+
+		found, err := updateGame(tx, game)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, "Game not found", http.StatusNotFound)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := invalidateGameUpdate(db, c, game, old.Status, tags); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(game)
+	}
+}
+
+// DeleteGameHandler godoc
+// @Summary Delete a game
+// @Description Delete a game that has no player or team stat lines recorded against it
+// @Tags games
+// @Param gameId path int true "gameId"
+// @Success 204
+// @Failure 400 {string} string "Invalid game ID"
+// @Failure 404 {string} string "Game not found"
+// @Failure 409 {string} string "Game has stat lines"
+// @Failure 500 {string} string "Internal server error"
+// @Router /games/{gameId} [delete]
+func DeleteGameHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID, err := strconv.Atoi(mux.Vars(r)["gameId"])
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusBadRequest)
+			return
+		}
+
+		var hasStats bool
+		err = db.QueryRow(`SELECT EXISTS (SELECT 1 FROM stats WHERE game_id = $1)
+                              OR EXISTS (SELECT 1 FROM team_stats WHERE game_id = $1)`, gameID).Scan(&hasStats)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if hasStats {
+			http.Error(w, "Game has stat lines", http.StatusConflict)
+			return
+		}
+
+		var status string
+		err = db.QueryRow(`DELETE FROM games WHERE id = $1 RETURNING status`, gameID).Scan(&status)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Game not found", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if status == "final" {
+			if err := c.Invalidate(standingsTag); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func getGame(ex execer, gameID int) (*models.Game, error) {
+	query := `SELECT id, home_team_id, away_team_id, game_date, home_score, away_score, status, overtime_periods
+              FROM games WHERE id = $1`
+	var game models.Game
+	err := ex.QueryRow(query, gameID).Scan(&game.ID, &game.HomeTeamID, &game.AwayTeamID, &game.GameDate,
+		&game.HomeScore, &game.AwayScore, &game.Status, &game.OvertimePeriods)
+	if err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+// insertGame creates the game and sets its ID
+func insertGame(ex execer, game *models.Game) error {
+	query := `INSERT INTO games (home_team_id, away_team_id, game_date, home_score, away_score, status, overtime_periods)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	return ex.QueryRow(query, game.HomeTeamID, game.AwayTeamID, game.GameDate, game.HomeScore, game.AwayScore, game.Status,
+		game.OvertimePeriods).Scan(&game.ID)
+}
+
+// updateGame replaces the game's fields and the date of its stat lines, in
+// tx so that the two do not drift apart. found is false if there is no such
+// game.
+func updateGame(tx *sql.Tx, game models.Game) (found bool, err error) {
+	query := `UPDATE games
+              SET home_team_id = $2, away_team_id = $3, game_date = $4, home_score = $5, away_score = $6, status = $7,
+                  overtime_periods = $8
+              WHERE id = $1`
+	res, err := tx.Exec(query, game.ID, game.HomeTeamID, game.AwayTeamID, game.GameDate, game.HomeScore, game.AwayScore, game.Status,
+		game.OvertimePeriods)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	// keep the denormalised date on stat lines in step with the game
+	_, err = tx.Exec(`UPDATE stats SET game_date = $2 WHERE game_id = $1`, game.ID, game.GameDate)
+	return err == nil, err
+}
+
+// invalidateGameUpdate drops what an update to a game may change: the
+// averages of the players with lines in it and of their teams, given by tags
+// as read before the update since a new date can credit the lines to other
+// teams, and the standings when the game was or is final.
+func invalidateGameUpdate(db *sql.DB, c cache.Cache, game models.Game, oldStatus string, tags []string) error {
+	after, err := gameTags(db, game.ID)
+	if err != nil {
+		return err
+	}
+	tags = append(tags, after...)
+	if oldStatus == "final" || game.Status == "final" {
+		tags = append(tags, standingsTag)
+	}
+	return c.Invalidate(tags...)
+}
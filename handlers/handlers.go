@@ -5,13 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"nba_stats/cache"
 	"nba_stats/models"
 	"net/http"
 	"strconv"
-	"time"
 
-	"github.com/go-redis/redis"
 	"github.com/gorilla/mux"
+	"github.com/lib/pq"
 )
 
 // AddPlayerHandler godoc
@@ -23,22 +23,42 @@ import (
 // @Param player body models.Player true "Player"
 // @Success 201 {object} models.Player
 // @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
 // @Failure 500 {string} string "Internal server error"
 // @Router /add-players [post]
-func AddPlayerHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
+func AddPlayerHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var player models.Player
 		if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
+		errs := validatePlayer(player)
+		refErrs, err := checkPlayerRefs(db, player)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
 
-		query := `INSERT INTO players (name, team_id) VALUES ($1, $2) RETURNING id`
-		err := db.QueryRow(query, player.Name, player.TeamID).Scan(&player.ID)
+		tx, err := db.Begin()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		defer tx.Rollback()
+
+		if err := insertPlayer(tx, &player); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(player)
@@ -47,16 +67,17 @@ func AddPlayerHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 
 // AddStatHandler godoc
 // @Summary Add a new game stat
-// @Description Add a new game stat to the database
+// @Description Add a new game stat to the database. game_id is required and the stat line takes the game's date. Responds with the stat line and its id.
 // @Tags stats
 // @Accept json
 // @Produce json
 // @Param stat body models.GameStat true "Game Stat"
 // @Success 201 {object} models.GameStat
 // @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
 // @Failure 500 {string} string "Internal server error"
 // @Router /add-stat [post]
-func AddStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
+func AddStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var stat models.GameStat
 		err := json.NewDecoder(r.Body).Decode(&stat)
@@ -65,27 +86,83 @@ func AddStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 			return
 		}
 
-		query := `INSERT INTO stats (player_id, points, rebounds, assists, steals, blocks, fouls, turnovers, minutes_played, game_date)
-                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
-		_, err = db.Exec(query, stat.PlayerID, stat.Points, stat.Rebounds, stat.Assists, stat.Steals, stat.Blocks, stat.Fouls, stat.Turnovers, stat.MinutesPlayed, stat.GameDate)
+		rollUpPeriods(&stat)
+		var game *models.Game
+		var rules models.Rules
+		if stat.GameID > 0 {
+			game, err = getGame(db, stat.GameID)
+			if err != nil && err != sql.ErrNoRows {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+		if game != nil {
+			if rules, err = teamRules(db, game.HomeTeamID); err != nil {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+		errs := validateGameStat(stat, game, rules)
+		if stat.GameID > 0 && game == nil {
+			errs.add("game_id", "exists", "game %d does not exist", stat.GameID)
+		}
+		refErrs, err := checkGameStatRefs(db, stat)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		// the game is the source of truth for when the stat line happened
+		stat.GameDate = game.GameDate
+
+		query := `INSERT INTO stats (player_id, game_id, points, rebounds, offensive_rebounds, defensive_rebounds, assists, steals, blocks, fouls, turnovers,
+                      field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+                      free_throws_made, free_throws_attempted, minutes_played, plus_minus, started, game_date)
+                  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+                  RETURNING id`
+		tx, err := db.Begin()
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		defer tx.Rollback()
 
-		//cache invalidation
-		cacheKeyPlayer := fmt.Sprintf("player_stats_%d", stat.PlayerID)
-		rdb.Del(cacheKeyPlayer)
-		query = `SELECT team_id from players where id=$1`
-		var teamID int
-		err = db.QueryRow(query, stat.PlayerID).Scan(&teamID)
+		var statID int
+		err = tx.QueryRow(query, stat.PlayerID, stat.GameID, stat.Points, stat.Rebounds, stat.OffensiveRebounds, stat.DefensiveRebounds,
+			stat.Assists, stat.Steals, stat.Blocks, stat.Fouls, stat.Turnovers,
+			stat.FieldGoalsMade, stat.FieldGoalsAttempted, stat.ThreePointersMade, stat.ThreePointersAttempted,
+			stat.FreeThrowsMade, stat.FreeThrowsAttempted, stat.MinutesPlayed, stat.PlusMinus, stat.Started, stat.GameDate).Scan(&statID)
+		if isUniqueViolation(err) {
+			errs.duplicateStat(stat)
+			writeValidationErrors(w, errs)
+			return
+		}
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		cacheKeyTeam := fmt.Sprintf("team_stats_%d", stat.PlayerID)
-		rdb.Del(cacheKeyTeam)
+		if err := insertPeriods(tx, statID, stat.Periods); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		stat.ID = statID
+
+		//cache invalidation
+		if err := invalidateStatLine(db, c, stat); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(stat)
 	}
 }
 
@@ -97,9 +174,9 @@ func AddStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 // @Success 200 {array} models.Player
 // @Failure 500 {string} string "Internal server error"
 // @Router /players [get]
-func ListPlayersHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
+func ListPlayersHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(`SELECT id, name, team_id FROM players`)
+		rows, err := db.Query(`SELECT ` + playerColumns + ` FROM players`)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -108,8 +185,8 @@ func ListPlayersHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 
 		var players []models.Player
 		for rows.Next() {
-			var player models.Player
-			if err := rows.Scan(&player.ID, &player.Name, &player.TeamID); err != nil {
+			player, err := scanPlayer(rows)
+			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -127,10 +204,17 @@ func ListPlayersHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 // @Tags players
 // @Produce json
 // @Param playerId path int true "PlayerId"
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs"
+// @Param period query string false "Period number (1-4 quarters, 5+ overtimes) or ot for all overtimes"
+// @Param from query string false "First game date, YYYY-MM-DD"
+// @Param to query string false "Last game date, YYYY-MM-DD"
+// @Param last_n query int false "Only the last N games matching the other filters"
+// @Param per query string false "game (default), 36 for per 36 minutes, 100poss for per 100 possessions, or total"
 // @Success 200 {array} models.AvgStat
 // @Failure 500 {string} string "Internal server error"
 // @Router /stat/players/{playerId} [get]
-func GetPlayerAvgStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
+func GetPlayerAvgStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		playerID, err := strconv.Atoi(vars["playerId"])
@@ -138,43 +222,38 @@ func GetPlayerAvgStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 			fmt.Println("Error:", err)
 			return
 		}
-		cacheKey := fmt.Sprintf("player_stats_%d", playerID)
-
-		// Try to get cached data
-		cachedData, err := rdb.Get(cacheKey).Result()
-		if err == redis.Nil {
-			// Cache miss, fetch data from DB
-			stats, err := getAvgPlayerStats(db, playerID)
-			if err != nil {
-				if err == sql.ErrNoRows {
-					http.Error(w, "Player not found", http.StatusNotFound)
-				} else {
-					http.Error(w, "Internal server error", http.StatusInternalServerError)
-				}
-				return
-			}
+		filter, err := parseStatFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		scale, err := parseScaledFilter(r, filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cacheKey := scale.cacheKey(filter.cacheKey(fmt.Sprintf("player_stats_%d", playerID)))
 
-			data, err := json.Marshal(stats)
-			if err != nil {
-				http.Error(w, "json marshal error", http.StatusInternalServerError)
-				return
+		// per 100 possessions also depends on the box scores of the player's
+		// teams
+		data, err := cachedTagged(c, cacheKey, func() (interface{}, []string, error) {
+			avg, teamIDs, err := getAvgPlayerStats(db, playerID, filter, scale)
+			tags := []string{playerTag(playerID)}
+			for _, id := range teamIDs {
+				tags = append(tags, teamTag(id))
 			}
-
-			//Make data expire after 24 hour
-			err = rdb.Set(cacheKey, data, 24*time.Hour).Err()
-			if err != nil {
+			return avg, filter.tags(tags...), err
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Player not found", http.StatusNotFound)
+			} else {
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(stats)
-		} else if err != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		} else {
-			// Cache hit
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(cachedData))
+			return
 		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
 	}
 }
 
@@ -184,10 +263,17 @@ func GetPlayerAvgStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 // @Tags players
 // @Produce json
 // @Param teamId path int true "teamId"
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs"
+// @Param period query string false "Period number (1-4 quarters, 5+ overtimes) or ot for all overtimes"
+// @Param from query string false "First game date, YYYY-MM-DD"
+// @Param to query string false "Last game date, YYYY-MM-DD"
+// @Param last_n query int false "Only the last N games matching the other filters"
+// @Param per query string false "game (default), 36 for per 36 minutes, 100poss for per 100 possessions, or total"
 // @Success 200 {array} models.AvgStat
 // @Failure 500 {string} string "Internal server error"
 // @Router /stat/teams/{teamId} [get]
-func GetTeamAvgStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
+func GetTeamAvgStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		teamID, err := strconv.Atoi(vars["teamId"])
@@ -196,128 +282,110 @@ func GetTeamAvgStatHandler(db *sql.DB, rdb *redis.Client) http.HandlerFunc {
 			return
 		}
 
-		cacheKey := fmt.Sprintf("team_stats_%d", teamID)
-
-		// Try to get cached data
-		cachedData, err := rdb.Get(cacheKey).Result()
-		if err == redis.Nil {
-			// Cache miss, fetch data from DB
-			stats, err := getAvgTeamStats(db, teamID)
-			if err != nil {
-				if err == sql.ErrNoRows {
-					http.Error(w, "Player not found", http.StatusNotFound)
-				} else {
-					http.Error(w, "Internal server error", http.StatusInternalServerError)
-				}
-				return
-			}
-
-			data, err := json.Marshal(stats)
-			if err != nil {
-				http.Error(w, "json marshal error", http.StatusInternalServerError)
-				return
-			}
+		filter, err := parseStatFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		scale, err := parseScaledFilter(r, filter)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cacheKey := scale.cacheKey(filter.cacheKey(fmt.Sprintf("team_stats_%d", teamID)))
 
-			//Make data expire after 24 hour
-			err = rdb.Set(cacheKey, data, 24*time.Hour).Err()
-			if err != nil {
+		data, err := cached(c, cacheKey, filter.tags(teamTag(teamID)), func() (interface{}, error) {
+			return getAvgTeamStats(db, teamID, filter, scale)
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Team not found", http.StatusNotFound)
+			} else {
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
-				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(stats)
-		} else if err != nil {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		} else {
-			// Cache hit
-			w.Header().Set("Content-Type", "application/json")
-			w.Write([]byte(cachedData))
+			return
 		}
-
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
 	}
 }
 
-func getAvgPlayerStats(db *sql.DB, playerID int) (*models.AvgStat, error) {
-	query := fmt.Sprintf(`
-SELECT
-	AVG(points) AS avg_points,
-	AVG(rebounds) AS avg_rebounds,
-	AVG(assists) AS avg_assists,
-	AVG(steals) AS avg_steals,
-	AVG(blocks) AS avg_blocks,
-	AVG(fouls) AS avg_fouls,
-	AVG(turnovers) AS avg_turnovers,
-	AVG(minutes_played) AS avg_minutes_played
+// getAvgPlayerStats returns the player's averages and, per 100 possessions,
+// the teams whose box scores they were computed from
+func getAvgPlayerStats(db *sql.DB, playerID int, filter statFilter, scale statScale) (*models.AvgStat, []int, error) {
+	var query string
+	var conds string
+	args := []interface{}{playerID}
+	if filter.rollup() && !scale.basis() {
+		conds, args = filter.rollupWhere(args)
+		query = `SELECT` + rollupTotalsColumns + ` FROM stat_rollups WHERE stat_rollups.player_id = $1` + conds
+	} else {
+		conds, args = filter.lastNWhere(` WHERE stats.player_id = $1`, args)
+		column, joins := scale.basisColumn(5)
+		if scale == per100 {
+			column += `,
+	array_agg(DISTINCT scale_teams.team_id) FILTER (WHERE scale_teams.team_id IS NOT NULL)`
+		}
+		if column != "" {
+			column = "," + column
+		}
+		query = `
+SELECT` + statTotalsColumns + column + `
 FROM
-	stats
+	` + filter.from() + joins + `
 WHERE
-	player_id = %d;`, playerID)
+	stats.player_id = $1` + conds
+	}
 
-	rows, err := db.Query(query)
+	var basis float64
+	var teamIDs []int64
+	var extra []interface{}
+	if scale.basis() {
+		extra = append(extra, &basis)
+	}
+	if scale == per100 {
+		extra = append(extra, pq.Array(&teamIDs))
+	}
+	totals, err := scanTotals(db.QueryRow(query, args...), extra...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	defer rows.Close()
-
-	var avg_points float64
-	var avg_rebounds float64
-	var avg_assists float64
-	var avg_steals float64
-	var avg_blocks float64
-	var avg_fouls float64
-	var avg_turnovers float64
-	var avg_minutes_played float64
-	if rows.Next() {
-		err := rows.Scan(&avg_points, &avg_rebounds, &avg_assists, &avg_steals, &avg_blocks,
-			&avg_fouls, &avg_turnovers, &avg_minutes_played)
-		if err != nil {
-			return nil, err
-		}
-		stat := models.AvgStat{
-			AvgPoints:        avg_points,
-			AvgRebounds:      avg_rebounds,
-			AvgAssists:       avg_assists,
-			AvgSteals:        avg_steals,
-			AvgBlocks:        avg_blocks,
-			AvgFouls:         avg_fouls,
-			AvgTurnovers:     avg_turnovers,
-			AvgMinutesPlayed: avg_minutes_played,
-		}
-		return &stat, nil
-	} else {
-		return nil, errors.New("no rows found")
+	ids := make([]int, len(teamIDs))
+	for i, id := range teamIDs {
+		ids[i] = int(id)
 	}
+	return scale.apply(*totals, basis), ids, nil
 }
 
-func getAvgTeamStats(db *sql.DB, teamID int) (*models.AvgStat, error) {
-	query := `
-			SELECT
-			AVG(stats.points) AS avg_points,
-			AVG(stats.rebounds) AS avg_rebounds,
-			AVG(stats.assists) AS avg_assists,
-			AVG(stats.steals) AS avg_steals,
-			AVG(stats.blocks) AS avg_blocks,
-			AVG(stats.fouls) AS avg_fouls,
-			AVG(stats.turnovers) AS avg_turnovers,
-			AVG(stats.minutes_played) AS avg_minutes_played
+func getAvgTeamStats(db *sql.DB, teamID int, filter statFilter, scale statScale) (*models.AvgStat, error) {
+	var query string
+	var conds string
+	args := []interface{}{teamID}
+	if filter.rollup() && !scale.basis() {
+		conds, args = filter.rollupWhere(args)
+		query = `SELECT` + rollupTotalsColumns + ` FROM stat_rollups WHERE stat_rollups.team_id = $1 AND stat_rollups.team_id <> 0` + conds
+	} else {
+		conds, args = filter.lastNWhere(` JOIN stat_teams ON stat_teams.stat_id = stats.id WHERE stat_teams.team_id = $1`, args)
+		column, joins := scale.basisColumn(1)
+		if column != "" {
+			column = "," + column
+		}
+		query = `
+		SELECT` + statTotalsColumns + column + `
 		FROM
-			stats
+			` + filter.from() + `
 		JOIN
-			players ON players.id = stats.player_id
+			stat_teams ON stat_teams.stat_id = stats.id` + joins + `
 		WHERE
-			players.team_id = $1;
-	`
-	var stats models.AvgStat
-	err := db.QueryRow(query, teamID).Scan(
-		&stats.AvgPoints,
-		&stats.AvgRebounds,
-		&stats.AvgAssists,
-		&stats.AvgSteals,
-		&stats.AvgBlocks,
-		&stats.AvgFouls,
-		&stats.AvgTurnovers,
-		&stats.AvgMinutesPlayed,
-	)
+			stat_teams.team_id = $1` + conds
+	}
+
+	var basis float64
+	var extra []interface{}
+	if scale.basis() {
+		extra = append(extra, &basis)
+	}
+	totals, err := scanTotals(db.QueryRow(query, args...), extra...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, err
@@ -325,5 +393,5 @@ func getAvgTeamStats(db *sql.DB, teamID int) (*models.AvgStat, error) {
 			return nil, errors.New("database error")
 		}
 	}
-	return &stats, nil
+	return scale.apply(*totals, basis), nil
 }
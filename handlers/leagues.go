@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+)
+
+// AddLeagueHandler godoc
+// @Summary Add a new league
+// @Description Add a league, the top of the league → conference → division → team hierarchy. Rules left out default to the NBA's: four 12-minute periods, 5-minute overtimes and a six-foul limit.
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param league body models.League true "League"
+// @Success 201 {object} models.League
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /leagues [post]
+func AddLeagueHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var league models.League
+		if err := json.NewDecoder(r.Body).Decode(&league); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		league.Rules = withDefaults(league.Rules)
+		if errs := validateLeague(league); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		query := `INSERT INTO leagues (name, period_minutes, periods, overtime_minutes, foul_limit)
+                  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+		err := db.QueryRow(query, league.Name, league.PeriodMinutes, league.Periods, league.OvertimeMinutes,
+			league.FoulLimit).Scan(&league.ID)
+		if isUniqueViolation(err) {
+			var errs fieldErrors
+			errs.add("name", "unique", "league %q already exists", league.Name)
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(league)
+	}
+}
+
+// ListLeaguesHandler godoc
+// @Summary List all leagues
+// @Description Get a list of all leagues
+// @Tags leagues
+// @Produce json
+// @Success 200 {array} models.League
+// @Failure 500 {string} string "Internal server error"
+// @Router /leagues [get]
+func ListLeaguesHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.Query(`SELECT id, name, period_minutes, periods, overtime_minutes, foul_limit FROM leagues ORDER BY id`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		leagues := []models.League{}
+		for rows.Next() {
+			var league models.League
+			err := rows.Scan(&league.ID, &league.Name, &league.PeriodMinutes, &league.Periods, &league.OvertimeMinutes,
+				&league.FoulLimit)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			leagues = append(leagues, league)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(leagues)
+	}
+}
+
+// AddConferenceHandler godoc
+// @Summary Add a new conference
+// @Description Add a conference to a league
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param conference body models.Conference true "Conference"
+// @Success 201 {object} models.Conference
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /conferences [post]
+func AddConferenceHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var conference models.Conference
+		if err := json.NewDecoder(r.Body).Decode(&conference); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validateConference(conference)
+		refErrs, err := checkConferenceRefs(db, conference)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		query := `INSERT INTO conferences (league_id, name) VALUES ($1, $2) RETURNING id`
+		err = db.QueryRow(query, conference.LeagueID, conference.Name).Scan(&conference.ID)
+		if isUniqueViolation(err) {
+			var errs fieldErrors
+			errs.add("name", "unique", "league %d already has a conference %q", conference.LeagueID, conference.Name)
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(conference)
+	}
+}
+
+// ListConferencesHandler godoc
+// @Summary List all conferences
+// @Description Get a list of all conferences
+// @Tags leagues
+// @Produce json
+// @Success 200 {array} models.Conference
+// @Failure 500 {string} string "Internal server error"
+// @Router /conferences [get]
+func ListConferencesHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.Query(`SELECT id, league_id, name FROM conferences ORDER BY id`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		conferences := []models.Conference{}
+		for rows.Next() {
+			var conference models.Conference
+			if err := rows.Scan(&conference.ID, &conference.LeagueID, &conference.Name); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			conferences = append(conferences, conference)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(conferences)
+	}
+}
+
+// AddDivisionHandler godoc
+// @Summary Add a new division
+// @Description Add a division to a conference
+// @Tags leagues
+// @Accept json
+// @Produce json
+// @Param division body models.Division true "Division"
+// @Success 201 {object} models.Division
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /divisions [post]
+func AddDivisionHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var division models.Division
+		if err := json.NewDecoder(r.Body).Decode(&division); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validateDivision(division)
+		refErrs, err := checkDivisionRefs(db, division)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		query := `INSERT INTO divisions (conference_id, name) VALUES ($1, $2) RETURNING id`
+		err = db.QueryRow(query, division.ConferenceID, division.Name).Scan(&division.ID)
+		if isUniqueViolation(err) {
+			var errs fieldErrors
+			errs.add("name", "unique", "conference %d already has a division %q", division.ConferenceID, division.Name)
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(division)
+	}
+}
+
+// ListDivisionsHandler godoc
+// @Summary List all divisions
+// @Description Get a list of all divisions
+// @Tags leagues
+// @Produce json
+// @Success 200 {array} models.Division
+// @Failure 500 {string} string "Internal server error"
+// @Router /divisions [get]
+func ListDivisionsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.Query(`SELECT id, conference_id, name FROM divisions ORDER BY id`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		divisions := []models.Division{}
+		for rows.Next() {
+			var division models.Division
+			if err := rows.Scan(&division.ID, &division.ConferenceID, &division.Name); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			divisions = append(divisions, division)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(divisions)
+	}
+}
+
+// withDefaults fills the rules left out of a request with the NBA's
+func withDefaults(r models.Rules) models.Rules {
+	if r.PeriodMinutes == 0 {
+		r.PeriodMinutes = models.DefaultRules.PeriodMinutes
+	}
+	if r.Periods == 0 {
+		r.Periods = models.DefaultRules.Periods
+	}
+	if r.OvertimeMinutes == 0 {
+		r.OvertimeMinutes = models.DefaultRules.OvertimeMinutes
+	}
+	if r.FoulLimit == 0 {
+		r.FoulLimit = models.DefaultRules.FoulLimit
+	}
+	return r
+}
+
+// teamRules returns the rules a team plays under, which for a game are those
+// of its home team
+func teamRules(db *sql.DB, teamID int) (models.Rules, error) {
+	var r models.Rules
+	query := `SELECT period_minutes, periods, overtime_minutes, foul_limit FROM team_rules WHERE team_id = $1`
+	err := db.QueryRow(query, teamID).Scan(&r.PeriodMinutes, &r.Periods, &r.OvertimeMinutes, &r.FoulLimit)
+	return r, err
+}
+
+// divisionLeague returns the league a division belongs to
+func divisionLeague(db *sql.DB, divisionID int) (int, error) {
+	var leagueID int
+	query := `SELECT conferences.league_id FROM divisions
+              JOIN conferences ON conferences.id = divisions.conference_id
+              WHERE divisions.id = $1`
+	err := db.QueryRow(query, divisionID).Scan(&leagueID)
+	return leagueID, err
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+)
+
+// PER follows Hollinger's definition as given by Basketball-Reference. The
+// league constants of a season are computed from all of its stat lines, which
+// is too slow for a request, so a job computes them together with every
+// player's PER whenever the season's data changes, and requests read the
+// stored values. Team context comes from the games each player played, like
+// that of the advanced metrics.
+
+// errPERPending is returned for a season whose PER has never been computed
+var errPERPending = errors.New("PER for the season is still being computed")
+
+// SeasonPERHandler godoc
+// @Summary Player Efficiency Rating
+// @Description Get every player's unadjusted, pace-adjusted and league-scaled PER over a season, highest first, with the league constants they were computed with. PER is recomputed in the background after the season's data changes; league.stale is set while a recompute is pending.
+// @Tags players
+// @Produce json
+// @Param season query int false "Season year, e.g. 2024 for 2023-24. Defaults to the current season"
+// @Param season_type query string false "preseason, regular, play-in or playoffs. Defaults to regular"
+// @Success 200 {object} models.SeasonPER
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Season not found"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 503 {string} string "PER for the season is still being computed"
+// @Router /stat/per [get]
+func SeasonPERHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		season, err := resolveSeason(db, r)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Season not found", http.StatusNotFound)
+			} else if err == errInvalidSeason {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		// writes bump the season's version without touching the cache, so
+		// the key carries the versions to keep league.stale current; the
+		// job drops the season's tag once it has stored new values
+		var version, computedVersion int
+		err = db.QueryRow(`SELECT version, computed_version FROM league_constants WHERE season_id = $1`,
+			season.ID).Scan(&version, &computedVersion)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		cacheKey := statFilter{Season: season.Year, SeasonType: season.Type}.cacheKey("per") +
+			fmt.Sprintf(":version=%d:computed_version=%d", version, computedVersion)
+
+		data, err := cached(c, cacheKey, []string{seasonTag(season.Year)}, func() (interface{}, error) {
+			return getSeasonPER(db, *season)
+		})
+		if err != nil {
+			if err == errPERPending {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+func getSeasonPER(db *sql.DB, season models.Season) (*models.SeasonPER, error) {
+	result := models.SeasonPER{Season: season, Players: []models.PlayerPER{}}
+	lc := &result.League
+	var version, computedVersion int
+	err := db.QueryRow(`
+		SELECT version, computed_version, computed_at, factor, value_of_possession, defensive_rebound_pct, pace,
+			free_throws_per_foul, free_throw_attempts_per_foul, average_aper
+		FROM league_constants WHERE season_id = $1`, season.ID).Scan(&version, &computedVersion, &lc.ComputedAt,
+		&lc.Factor, &lc.ValueOfPossession, &lc.DefensiveReboundPct, &lc.Pace,
+		&lc.FreeThrowsPerFoul, &lc.FreeThrowAttemptsPerFoul, &lc.AverageAPER)
+	if err == sql.ErrNoRows || (err == nil && computedVersion == 0) {
+		return nil, errPERPending
+	} else if err != nil {
+		return nil, err
+	}
+	lc.Stale = computedVersion < version
+
+	rows, err := db.Query(`
+		SELECT player_efficiency.player_id, COALESCE(players.name, ''), player_efficiency.minutes_played,
+			player_efficiency.uper, player_efficiency.aper, player_efficiency.per
+		FROM player_efficiency
+		JOIN players ON players.id = player_efficiency.player_id
+		WHERE player_efficiency.season_id = $1
+		ORDER BY player_efficiency.per DESC, player_efficiency.player_id`, season.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.PlayerPER
+		if err := rows.Scan(&p.PlayerID, &p.PlayerName, &p.MinutesPlayed, &p.UnadjustedPER, &p.AdjustedPER, &p.PER); err != nil {
+			return nil, err
+		}
+		result.Players = append(result.Players, p)
+	}
+	return &result, rows.Err()
+}
+
+// RecomputeLeagueConstants recomputes the league constants and player PER of
+// every season whose data changed since they were last computed, then drops
+// the cached values derived from them. main runs it periodically. A season
+// that fails is logged and retried on the next run.
+func RecomputeLeagueConstants(db *sql.DB, c cache.Cache) error {
+	rows, err := db.Query(`
+		SELECT seasons.id, seasons.year, seasons.season_type, seasons.start_date, seasons.end_date
+		FROM league_constants
+		JOIN seasons ON seasons.id = league_constants.season_id
+		WHERE league_constants.computed_version < league_constants.version
+		ORDER BY seasons.start_date`)
+	if err != nil {
+		return err
+	}
+	var seasons []models.Season
+	for rows.Next() {
+		var s models.Season
+		if err := rows.Scan(&s.ID, &s.Year, &s.Type, &s.StartDate, &s.EndDate); err != nil {
+			rows.Close()
+			return err
+		}
+		seasons = append(seasons, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, season := range seasons {
+		if err := recomputeSeasonPER(db, season); err != nil {
+			log.Printf("Could not compute PER for the %d %s season: %v\n", season.Year, season.Type, err)
+			continue
+		}
+		if err := c.Invalidate(seasonTag(season.Year)); err != nil {
+			log.Printf("cache invalidate %s: %v\n", seasonTag(season.Year), err)
+		}
+	}
+	return nil
+}
+
+// recomputeSeasonPER computes and stores a season's constants and PER. It
+// reads a snapshot, so the version it records is the one its data matches; a
+// write to the season while it runs makes it fail to serialize, and the
+// season stays stale for the next run.
+func recomputeSeasonPER(db *sql.DB, season models.Season) error {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow(`SELECT version FROM league_constants WHERE season_id = $1`, season.ID).Scan(&version); err != nil {
+		return err
+	}
+	lines, err := seasonPERLines(tx, season)
+	if err != nil {
+		return err
+	}
+	lc, players := computePER(lines)
+
+	if _, err := tx.Exec(`DELETE FROM player_efficiency WHERE season_id = $1`, season.ID); err != nil {
+		return err
+	}
+	for _, p := range players {
+		_, err := tx.Exec(`INSERT INTO player_efficiency (season_id, player_id, minutes_played, uper, aper, per)
+			VALUES ($1, $2, $3, $4, $5, $6)`, season.ID, p.PlayerID, p.MinutesPlayed, p.UnadjustedPER, p.AdjustedPER, p.PER)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec(`
+		UPDATE league_constants
+		SET computed_version = $2, computed_at = now(), factor = $3, value_of_possession = $4, defensive_rebound_pct = $5,
+			pace = $6, free_throws_per_foul = $7, free_throw_attempts_per_foul = $8, average_aper = $9
+		WHERE season_id = $1`, season.ID, version, lc.Factor, lc.ValueOfPossession, lc.DefensiveReboundPct,
+		lc.Pace, lc.FreeThrowsPerFoul, lc.FreeThrowAttemptsPerFoul, lc.AverageAPER)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// perLine is a player's season totals with the team context PER needs
+type perLine struct {
+	PlayerID            int
+	Totals              statTotals
+	TeamAssists         float64
+	TeamFieldGoalsMade  float64
+	TeamPossessions     float64
+	OpponentPossessions float64
+	TeamMinutesPlayed   float64
+}
+
+func seasonPERLines(tx *sql.Tx, season models.Season) ([]perLine, error) {
+	conds, args := statFilter{Season: season.Year, SeasonType: season.Type}.where(nil)
+	rows, err := tx.Query(`
+		WITH season_lines AS (`+gameLines(`TRUE`+conds)+`)
+		SELECT`+statTotalsColumns+`,
+			COALESCE(SUM(team.assists), 0),
+			COALESCE(SUM(team.field_goals_made), 0),
+			COALESCE(SUM(`+possessions("team")+`), 0),
+			COALESCE(SUM(`+possessions("opponent")+`), 0),
+			COALESCE(SUM(team.minutes_played), 0),
+			stats.player_id
+		FROM season_lines AS stats
+		CROSS JOIN `+teamBox("stats.game_id", "stats.team_id")+` AS team
+		CROSS JOIN `+teamBox("stats.game_id", "stats.opponent_id")+` AS opponent
+		GROUP BY stats.player_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []perLine
+	for rows.Next() {
+		var l perLine
+		totals, err := scanTotals(rows, &l.TeamAssists, &l.TeamFieldGoalsMade,
+			&l.TeamPossessions, &l.OpponentPossessions, &l.TeamMinutesPlayed, &l.PlayerID)
+		if err != nil {
+			return nil, err
+		}
+		l.Totals = *totals
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// computePER derives the league constants from the sum of every player's
+// totals, and each player's PER from them
+func computePER(lines []perLine) (models.LeagueConstants, []models.PlayerPER) {
+	var lg statTotals
+	for _, l := range lines {
+		lg.add(l.Totals)
+	}
+	lc := models.LeagueConstants{
+		Factor:                   2.0/3 - ratio(0.5*ratio(lg.Assists, lg.FieldGoalsMade), 2*ratio(lg.FieldGoalsMade, lg.FreeThrowsMade)),
+		ValueOfPossession:        ratio(lg.Points, lg.FieldGoalsAttempted-lg.OffensiveRebounds+lg.Turnovers+0.44*lg.FreeThrowsAttempted),
+		DefensiveReboundPct:      ratio(lg.Rebounds-lg.OffensiveRebounds, lg.Rebounds),
+		Pace:                     48 * ratio(lg.FieldGoalsAttempted+0.44*lg.FreeThrowsAttempted-lg.OffensiveRebounds+lg.Turnovers, lg.MinutesPlayed/5),
+		FreeThrowsPerFoul:        ratio(lg.FreeThrowsMade, lg.Fouls),
+		FreeThrowAttemptsPerFoul: ratio(lg.FreeThrowsAttempted, lg.Fouls),
+	}
+
+	players := make([]models.PlayerPER, 0, len(lines))
+	var weighted float64
+	for _, l := range lines {
+		uper := l.Totals.unadjustedPER(lc, l.TeamAssists, l.TeamFieldGoalsMade)
+		teamPace := 48 * ratio((l.TeamPossessions+l.OpponentPossessions)/2, l.TeamMinutesPlayed/5)
+		aper := uper * ratio(lc.Pace, teamPace)
+		weighted += aper * l.Totals.MinutesPlayed
+		players = append(players, models.PlayerPER{
+			PlayerID:      l.PlayerID,
+			MinutesPlayed: l.Totals.MinutesPlayed,
+			UnadjustedPER: uper,
+			AdjustedPER:   aper,
+		})
+	}
+	lc.AverageAPER = ratio(weighted, lg.MinutesPlayed)
+	for i := range players {
+		players[i].PER = players[i].AdjustedPER * ratio(15, lc.AverageAPER)
+	}
+	return lc, players
+}
+
+// unadjustedPER is Hollinger's uPER: the per-minute value of the totals, with
+// made shots discounted by how often the team assists them and misses,
+// turnovers and fouls valued at the league's value of a possession
+func (t statTotals) unadjustedPER(lc models.LeagueConstants, teamAssists, teamFieldGoals float64) float64 {
+	assisted := ratio(teamAssists, teamFieldGoals)
+	vop, drb := lc.ValueOfPossession, lc.DefensiveReboundPct
+	value := t.ThreePointersMade +
+		2.0/3*t.Assists +
+		(2-lc.Factor*assisted)*t.FieldGoalsMade +
+		t.FreeThrowsMade*0.5*(1+(1-assisted)+2.0/3*assisted) -
+		vop*t.Turnovers -
+		vop*drb*(t.FieldGoalsAttempted-t.FieldGoalsMade) -
+		vop*0.44*(0.44+0.56*drb)*(t.FreeThrowsAttempted-t.FreeThrowsMade) +
+		vop*(1-drb)*(t.Rebounds-t.OffensiveRebounds) +
+		vop*drb*t.OffensiveRebounds +
+		vop*t.Steals +
+		vop*drb*t.Blocks -
+		t.Fouls*(lc.FreeThrowsPerFoul-0.44*lc.FreeThrowAttemptsPerFoul*vop)
+	return ratio(value, t.MinutesPlayed)
+}
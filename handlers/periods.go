@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"database/sql"
+	"nba_stats/models"
+)
+
+// rollUpPeriods fills in derived totals. Each period's rebounds are derived
+// from its split when omitted, and a stat line that only gives periods takes
+// its totals from them.
+func rollUpPeriods(stat *models.GameStat) {
+	var sum models.BoxScore
+	for i := range stat.Periods {
+		p := &stat.Periods[i]
+		if p.Rebounds == 0 {
+			p.Rebounds = p.OffensiveRebounds + p.DefensiveRebounds
+		}
+		sum = sum.Add(p.BoxScore)
+	}
+	if len(stat.Periods) > 0 && stat.BoxScore == (models.BoxScore{}) {
+		stat.BoxScore = sum
+	}
+	if stat.Rebounds == 0 {
+		stat.Rebounds = stat.OffensiveRebounds + stat.DefensiveRebounds
+	}
+}
+
+func insertPeriods(tx *sql.Tx, statID int, periods []models.PeriodStat) error {
+	query := `INSERT INTO stat_periods (stat_id, period, points, rebounds, offensive_rebounds, defensive_rebounds,
+                  assists, steals, blocks, fouls, turnovers,
+                  field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+                  free_throws_made, free_throws_attempted, minutes_played, plus_minus)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
+	for _, p := range periods {
+		_, err := tx.Exec(query, statID, p.Period, p.Points, p.Rebounds, p.OffensiveRebounds, p.DefensiveRebounds,
+			p.Assists, p.Steals, p.Blocks, p.Fouls, p.Turnovers,
+			p.FieldGoalsMade, p.FieldGoalsAttempted, p.ThreePointersMade, p.ThreePointersAttempted,
+			p.FreeThrowsMade, p.FreeThrowsAttempted, p.MinutesPlayed, p.PlusMinus)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// playerColumns is the select list read by scanPlayer
+const playerColumns = `players.id, players.name, COALESCE(players.team_id, 0), COALESCE(players.position, ''),
+	players.jersey_number, players.height_cm, players.weight_kg, players.birth_date,
+	COALESCE(players.nationality, ''), players.draft_year`
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func scanPlayer(row scanner) (models.Player, error) {
+	var player models.Player
+	var jersey, height, weight, draft sql.NullInt64
+	var birth sql.NullTime
+	err := row.Scan(&player.ID, &player.Name, &player.TeamID, &player.Position,
+		&jersey, &height, &weight, &birth, &player.Nationality, &draft)
+	if err != nil {
+		return player, err
+	}
+	player.JerseyNumber = intPtr(jersey)
+	player.HeightCm = intPtr(height)
+	player.WeightKg = intPtr(weight)
+	player.DraftYear = intPtr(draft)
+	if birth.Valid {
+		player.BirthDate = &birth.Time
+	}
+	return player, nil
+}
+
+func intPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+// GetPlayerProfileHandler godoc
+// @Summary Player profile
+// @Description Get a player's bio together with career and current regular season averages
+// @Tags players
+// @Produce json
+// @Param playerId path int true "PlayerId"
+// @Success 200 {object} models.PlayerProfile
+// @Failure 400 {string} string "Invalid player ID"
+// @Failure 404 {string} string "Player not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /players/{playerId} [get]
+func GetPlayerProfileHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, err := strconv.Atoi(mux.Vars(r)["playerId"])
+		if err != nil {
+			http.Error(w, "Invalid player ID", http.StatusBadRequest)
+			return
+		}
+
+		player, err := scanPlayer(db.QueryRow(`SELECT `+playerColumns+` FROM players WHERE id = $1`, playerID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Player not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		profile := models.PlayerProfile{Player: player}
+
+		profile.Career, _, err = getAvgPlayerStats(db, playerID, statFilter{}, perGame)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		season, err := currentSeason(db)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if season != nil {
+			filter := statFilter{Season: season.Year, SeasonType: season.Type}
+			profile.CurrentSeason, _, err = getAvgPlayerStats(db, playerID, filter, perGame)
+			if err != nil && err != sql.ErrNoRows {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(profile)
+	}
+}
+
+// currentSeason returns the most recent regular season that has started.
+func currentSeason(db *sql.DB) (*models.Season, error) {
+	query := `SELECT id, year, season_type, start_date, end_date FROM seasons
+              WHERE season_type = 'regular' AND start_date <= CURRENT_DATE
+              ORDER BY start_date DESC LIMIT 1`
+	var season models.Season
+	err := db.QueryRow(query).Scan(&season.ID, &season.Year, &season.Type, &season.StartDate, &season.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// insertPlayer creates the player, setting its ID, together with its first
+// stint on its team
+func insertPlayer(tx *sql.Tx, player *models.Player) error {
+	query := `INSERT INTO players (name, team_id, position, jersey_number, height_cm, weight_kg, birth_date, nationality, draft_year)
+              VALUES ($1, NULLIF($2, 0), NULLIF($3, ''), $4, $5, $6, $7, NULLIF($8, ''), $9) RETURNING id`
+	err := tx.QueryRow(query, player.Name, player.TeamID, player.Position, player.JerseyNumber, player.HeightCm,
+		player.WeightKg, player.BirthDate, player.Nationality, player.DraftYear).Scan(&player.ID)
+	if err != nil {
+		return err
+	}
+	// the first stint is open-ended in both directions so that games
+	// imported after the player is created are credited to the team
+	if player.TeamID != 0 {
+		query = `INSERT INTO player_teams (player_id, team_id) VALUES ($1, $2)`
+		if _, err := tx.Exec(query, player.ID, player.TeamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updatePlayer replaces the player's name, position and bio. The team is
+// left alone, as moves go through transactions, and read back into
+// player.TeamID. found is false if there is no such player.
+func updatePlayer(ex execer, player *models.Player) (found bool, err error) {
+	query := `UPDATE players
+              SET name = $2, position = NULLIF($3, ''), jersey_number = $4, height_cm = $5, weight_kg = $6,
+                  birth_date = $7, nationality = NULLIF($8, ''), draft_year = $9
+              WHERE id = $1
+              RETURNING COALESCE(team_id, 0)`
+	err = ex.QueryRow(query, player.ID, player.Name, player.Position, player.JerseyNumber, player.HeightCm,
+		player.WeightKg, player.BirthDate, player.Nationality, player.DraftYear).Scan(&player.TeamID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
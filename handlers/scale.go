@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"nba_stats/models"
+	"net/http"
+)
+
+// statScale is what averages are expressed per, set by the per query
+// parameter: game (the default), 36 minutes, 100 possessions or total.
+//
+// Per 36 minutes scales to three quarters of a regulation game under the
+// rules of each game's league, which is 36 minutes in the NBA and 30 in a
+// 40-minute league. Per 100 possessions divides by the team possessions a
+// player was on the floor for, estimated from the team's box score.
+type statScale string
+
+const (
+	perGame  statScale = "game"
+	per36    statScale = "36"
+	per100   statScale = "100poss"
+	perTotal statScale = "total"
+)
+
+func parseStatScale(r *http.Request) (statScale, error) {
+	switch v := statScale(r.URL.Query().Get("per")); v {
+	case "":
+		return perGame, nil
+	case perGame, per36, per100, perTotal:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid per %q", v)
+	}
+}
+
+// parseScaledFilter parses the per query parameter of a request filtered by
+// filter. Team possessions are only known per game, so per 100 possessions
+// cannot be combined with a period.
+func parseScaledFilter(r *http.Request, filter statFilter) (statScale, error) {
+	s, err := parseStatScale(r)
+	if err == nil && s == per100 && filter.Period != "" {
+		err = fmt.Errorf("per=%s cannot be combined with period: possessions are kept per game", per100)
+	}
+	return s, err
+}
+
+// cacheKey appends the scale to a key. Per-game averages keep the key itself.
+func (s statScale) cacheKey(key string) string {
+	if s == perGame {
+		return key
+	}
+	return key + ":per=" + string(s)
+}
+
+// basis reports whether the scale needs a basis column beside the totals
+func (s statScale) basis() bool {
+	return s == per36 || s == per100
+}
+
+// basisColumn returns the column that sums the scale's basis over the stat
+// lines, reachable as "stats", and the joins it needs. onFloor is the number
+// of players a team has on the floor when the lines are a player's, and 1
+// when they are a whole team's: a line's share of its team's possessions is
+// the team's possessions times the line's minutes over the team's minutes.
+func (s statScale) basisColumn(onFloor int) (column, joins string) {
+	switch s {
+	case per36:
+		return `
+	COALESCE(SUM(COALESCE((SELECT team_rules.periods * team_rules.period_minutes FROM games
+		JOIN team_rules ON team_rules.team_id = games.home_team_id
+		WHERE games.id = stats.game_id), 48)), 0)`, ""
+	case per100:
+		column = fmt.Sprintf(`
+	COALESCE(SUM(%d * (%s) * stats.minutes_played / NULLIF(scale_team.minutes_played, 0)), 0)`,
+			onFloor, possessions("scale_team"))
+		joins = `
+	LEFT JOIN stat_teams AS scale_teams ON scale_teams.stat_id = stats.id
+	LEFT JOIN ` + teamBox("stats.game_id", "scale_teams.team_id") + ` AS scale_team ON true`
+		return column, joins
+	}
+	return "", ""
+}
+
+// apply expresses the totals per the scale. basis is the value of the basis
+// column for per 36 minutes (the regulation minutes of the games) and per 100
+// possessions (the possessions played).
+func (s statScale) apply(t statTotals, basis float64) *models.AvgStat {
+	var avg *models.AvgStat
+	switch s {
+	case perTotal:
+		avg = t.scaled(1)
+	case per36:
+		minutes := 0.75 * basis / float64(t.Games)
+		avg = t.scaled(ratio(t.MinutesPlayed, minutes))
+		avg.PerMinutes = minutes
+	case per100:
+		avg = t.scaled(basis / 100)
+	default:
+		avg = t.averages()
+	}
+	avg.Per = string(s)
+	return avg
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+)
+
+// seasonTypes are the phases a season row can describe
+var seasonTypes = map[string]bool{
+	"preseason": true,
+	"regular":   true,
+	"play-in":   true,
+	"playoffs":  true,
+}
+
+// AddSeasonHandler godoc
+// @Summary Add a new season
+// @Description Add a season phase with the date range its games are played in
+// @Tags seasons
+// @Accept json
+// @Produce json
+// @Param season body models.Season true "Season"
+// @Success 201 {object} models.Season
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /seasons [post]
+func AddSeasonHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var season models.Season
+		if err := json.NewDecoder(r.Body).Decode(&season); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errs := validateSeason(season); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		query := `INSERT INTO seasons (year, season_type, start_date, end_date) VALUES ($1, $2, $3, $4) RETURNING id`
+		err := db.QueryRow(query, season.Year, season.Type, season.StartDate, season.EndDate).Scan(&season.ID)
+		if isUniqueViolation(err) {
+			var errs fieldErrors
+			errs.add("type", "unique", "the %d %s season already exists", season.Year, season.Type)
+			writeValidationErrors(w, errs)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// averages filtered by season now cover the new date range
+		if err := c.Invalidate(seasonTag(season.Year), seasonsTag); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(season)
+	}
+}
+
+// ListSeasonsHandler godoc
+// @Summary List all seasons
+// @Description Get a list of all seasons ordered by start date
+// @Tags seasons
+// @Produce json
+// @Success 200 {array} models.Season
+// @Failure 500 {string} string "Internal server error"
+// @Router /seasons [get]
+func ListSeasonsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.Query(`SELECT id, year, season_type, start_date, end_date FROM seasons ORDER BY start_date`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		seasons := []models.Season{}
+		for rows.Next() {
+			var season models.Season
+			if err := rows.Scan(&season.ID, &season.Year, &season.Type, &season.StartDate, &season.EndDate); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			seasons = append(seasons, season)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(seasons)
+	}
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// StandingsHandler godoc
+// @Summary Standings
+// @Description Get conference standings derived from final game scores. Defaults to the current regular season.
+// @Tags standings
+// @Produce json
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs (default regular)"
+// @Success 200 {object} models.Standings
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Season not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /standings [get]
+func StandingsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return seasonTableHandler(db, c, "", func(t *seasonTable) interface{} { return t.standings() })
+}
+
+// seasonTableHandler serves a view of the season table named by the request,
+// cached under the standings key. suffix tells the views apart in the cache.
+func seasonTableHandler(db *sql.DB, c cache.Cache, suffix string, view func(*seasonTable) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		season, err := resolveSeason(db, r)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Season not found", http.StatusNotFound)
+			} else if err == errInvalidSeason {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+		cacheKey := statFilter{Season: season.Year, SeasonType: season.Type}.cacheKey("standings") + suffix
+
+		tags := []string{standingsTag, seasonTag(season.Year)}
+		data, err := cached(c, cacheKey, tags, func() (interface{}, error) {
+			// compute from game results
+			table, err := loadSeasonTable(db, *season)
+			if err != nil {
+				return nil, err
+			}
+			return view(table), nil
+		})
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+var errInvalidSeason = errors.New("invalid season or season_type")
+
+// resolveSeason returns the season named by the season and season_type query
+// parameters, or the current regular season when season is not given.
+func resolveSeason(db *sql.DB, r *http.Request) (*models.Season, error) {
+	q := r.URL.Query()
+	if q.Get("season") == "" {
+		return currentSeason(db)
+	}
+	year, err := strconv.Atoi(q.Get("season"))
+	if err != nil {
+		return nil, errInvalidSeason
+	}
+	seasonType := q.Get("season_type")
+	if seasonType == "" {
+		seasonType = "regular"
+	}
+	if !seasonTypes[seasonType] {
+		return nil, errInvalidSeason
+	}
+
+	query := `SELECT id, year, season_type, start_date, end_date FROM seasons WHERE year = $1 AND season_type = $2`
+	var season models.Season
+	err = db.QueryRow(query, year, seasonType).Scan(&season.ID, &season.Year, &season.Type, &season.StartDate, &season.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// standingsTeam is a team with its place in the league hierarchy
+type standingsTeam struct {
+	ID             int
+	Name           string
+	DivisionID     int
+	DivisionName   string
+	ConferenceID   int
+	ConferenceName string
+}
+
+// seasonTable holds everything standings are derived from: the teams and
+// the final games of one season in the order they were played.
+type seasonTable struct {
+	season models.Season
+	teams  map[int]standingsTeam
+	games  []models.Game
+}
+
+func loadSeasonTable(db *sql.DB, season models.Season) (*seasonTable, error) {
+	table := &seasonTable{season: season, teams: map[int]standingsTeam{}}
+
+	rows, err := db.Query(`
+		SELECT teams.id, teams.name,
+			COALESCE(divisions.id, 0), COALESCE(divisions.name, ''),
+			COALESCE(conferences.id, 0), COALESCE(conferences.name, '')
+		FROM teams
+		LEFT JOIN divisions ON divisions.id = teams.division_id
+		LEFT JOIN conferences ON conferences.id = divisions.conference_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t standingsTeam
+		if err := rows.Scan(&t.ID, &t.Name, &t.DivisionID, &t.DivisionName, &t.ConferenceID, &t.ConferenceName); err != nil {
+			return nil, err
+		}
+		table.teams[t.ID] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	gameRows, err := db.Query(`
+		SELECT id, home_team_id, away_team_id, game_date, home_score, away_score, status, overtime_periods
+		FROM games
+		WHERE status = 'final' AND game_date BETWEEN $1 AND $2
+		ORDER BY game_date, id`, season.StartDate, season.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer gameRows.Close()
+	for gameRows.Next() {
+		var g models.Game
+		if err := gameRows.Scan(&g.ID, &g.HomeTeamID, &g.AwayTeamID, &g.GameDate,
+			&g.HomeScore, &g.AwayScore, &g.Status, &g.OvertimePeriods); err != nil {
+			return nil, err
+		}
+		table.games = append(table.games, g)
+	}
+	return table, gameRows.Err()
+}
+
+// records derives every team's record from the games
+func (t *seasonTable) records() map[int]*models.TeamRecord {
+	records := map[int]*models.TeamRecord{}
+	results := map[int][]bool{}
+	for id, team := range t.teams {
+		records[id] = &models.TeamRecord{
+			TeamID:       id,
+			TeamName:     team.Name,
+			ConferenceID: team.ConferenceID,
+			DivisionID:   team.DivisionID,
+			Division:     team.DivisionName,
+		}
+	}
+
+	for _, g := range t.games {
+		home, away := records[g.HomeTeamID], records[g.AwayTeamID]
+		if home == nil || away == nil || g.HomeScore == g.AwayScore {
+			continue
+		}
+		homeWon := g.HomeScore > g.AwayScore
+		if homeWon {
+			home.Wins++
+			home.HomeWins++
+			away.Losses++
+			away.AwayLosses++
+		} else {
+			away.Wins++
+			away.AwayWins++
+			home.Losses++
+			home.HomeLosses++
+		}
+		home.PointsFor += g.HomeScore
+		home.PointsAgainst += g.AwayScore
+		away.PointsFor += g.AwayScore
+		away.PointsAgainst += g.HomeScore
+		results[home.TeamID] = append(results[home.TeamID], homeWon)
+		results[away.TeamID] = append(results[away.TeamID], !homeWon)
+	}
+
+	for id, rec := range records {
+		rec.WinPct = winPct(rec.Wins, rec.Losses)
+		rec.LastTen, rec.Streak = recentForm(results[id])
+	}
+	return records
+}
+
+// standings groups the records by conference, best record first
+func (t *seasonTable) standings() *models.Standings {
+	records := t.records()
+	byConference := map[int]*models.ConferenceStandings{}
+	for id, rec := range records {
+		team := t.teams[id]
+		conf := byConference[team.ConferenceID]
+		if conf == nil {
+			name := team.ConferenceName
+			if team.ConferenceID == 0 {
+				name = "Unassigned"
+			}
+			conf = &models.ConferenceStandings{ConferenceID: team.ConferenceID, Name: name}
+			byConference[team.ConferenceID] = conf
+		}
+		conf.Teams = append(conf.Teams, *rec)
+	}
+
+	standings := &models.Standings{Season: t.season, Conferences: []models.ConferenceStandings{}}
+	for _, conf := range byConference {
+		sortRecords(conf.Teams)
+		setGamesBehind(conf.Teams)
+		standings.Conferences = append(standings.Conferences, *conf)
+	}
+	sort.Slice(standings.Conferences, func(i, j int) bool {
+		return standings.Conferences[i].ConferenceID < standings.Conferences[j].ConferenceID
+	})
+	return standings
+}
+
+// sortRecords orders records by win percentage, then wins, then name
+func sortRecords(records []models.TeamRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.WinPct != b.WinPct {
+			return a.WinPct > b.WinPct
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.TeamName < b.TeamName
+	})
+}
+
+// setGamesBehind measures every record against the first one
+func setGamesBehind(records []models.TeamRecord) {
+	if len(records) == 0 {
+		return
+	}
+	leader := records[0]
+	for i := range records {
+		records[i].GamesBehind = float64((leader.Wins-records[i].Wins)+(records[i].Losses-leader.Losses)) / 2
+	}
+}
+
+func winPct(wins, losses int) float64 {
+	if wins+losses == 0 {
+		return 0
+	}
+	return float64(wins) / float64(wins+losses)
+}
+
+// recentForm returns the record over the last ten results and the current
+// streak, given results in the order the games were played.
+func recentForm(results []bool) (lastTen, streak string) {
+	var wins, losses int
+	for i := len(results) - 1; i >= 0 && i >= len(results)-10; i-- {
+		if results[i] {
+			wins++
+		} else {
+			losses++
+		}
+	}
+	lastTen = fmt.Sprintf("%d-%d", wins, losses)
+
+	if len(results) == 0 {
+		return lastTen, ""
+	}
+	last := results[len(results)-1]
+	n := 0
+	for i := len(results) - 1; i >= 0 && results[i] == last; i-- {
+		n++
+	}
+	if last {
+		return lastTen, fmt.Sprintf("W%d", n)
+	}
+	return lastTen, fmt.Sprintf("L%d", n)
+}
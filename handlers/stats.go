@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// statColumns is the select list read by scanStat
+const statColumns = `stats.id, stats.player_id, COALESCE(stats.game_id, 0), stats.points, stats.rebounds,
+	stats.offensive_rebounds, stats.defensive_rebounds, stats.assists, stats.steals, stats.blocks, stats.fouls, stats.turnovers,
+	stats.field_goals_made, stats.field_goals_attempted, stats.three_pointers_made, stats.three_pointers_attempted,
+	stats.free_throws_made, stats.free_throws_attempted, stats.minutes_played, stats.plus_minus, stats.started, stats.game_date`
+
+func scanStat(row scanner) (models.GameStat, error) {
+	var s models.GameStat
+	err := row.Scan(&s.ID, &s.PlayerID, &s.GameID, &s.Points, &s.Rebounds,
+		&s.OffensiveRebounds, &s.DefensiveRebounds, &s.Assists, &s.Steals, &s.Blocks, &s.Fouls, &s.Turnovers,
+		&s.FieldGoalsMade, &s.FieldGoalsAttempted, &s.ThreePointersMade, &s.ThreePointersAttempted,
+		&s.FreeThrowsMade, &s.FreeThrowsAttempted, &s.MinutesPlayed, &s.PlusMinus, &s.Started, &s.GameDate)
+	return s, err
+}
+
+// PatchStatHandler godoc
+// @Summary Correct a game stat
+// @Description Update the fields given in the body of a stat line. Giving periods replaces the period breakdown, and when no game totals are given alongside them the totals are rolled up from the new periods. Rebounds follow a changed split unless given. The result is validated like a new stat line.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path int true "Stat line id"
+// @Param stat body models.GameStat true "Fields to change"
+// @Success 200 {object} models.GameStat
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Stat line not found"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /stats/{id} [patch]
+func PatchStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid stat ID", http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		old, err := getStat(tx, statID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Stat line not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// decode over a copy of the stored line so that omitted fields keep
+		// their values; periods are only replaced when given
+		stat := *old
+		stat.Periods = nil
+		if err := json.Unmarshal(body, &stat); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		stat.ID = old.ID
+		if _, ok := fields["periods"]; !ok {
+			stat.Periods = old.Periods
+		} else if len(stat.Periods) > 0 && !hasBoxColumn(fields) {
+			stat.BoxScore = models.BoxScore{}
+		}
+		if _, ok := fields["rebounds"]; !ok && (fields["offensive_rebounds"] != nil || fields["defensive_rebounds"] != nil) {
+			stat.Rebounds = 0
+		}
+		rollUpPeriods(&stat)
+
+		var game *models.Game
+		var rules models.Rules
+		if stat.GameID > 0 {
+			game, err = getGame(tx, stat.GameID)
+			if err != nil && err != sql.ErrNoRows {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+		if game != nil {
+			if rules, err = teamRules(db, game.HomeTeamID); err != nil {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+		}
+		errs := validateGameStat(stat, game, rules)
+		if stat.GameID > 0 && game == nil {
+			errs.add("game_id", "exists", "game %d does not exist", stat.GameID)
+		}
+		refErrs, err := checkGameStatRefs(tx, stat)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		stat.GameDate = game.GameDate
+
+		if err := updateStat(tx, stat); err != nil {
+			if isUniqueViolation(err) {
+				errs.duplicateStat(stat)
+				writeValidationErrors(w, errs)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// the line may have moved to another player or game, so both the
+		// old and the new owners' averages change
+		if err := invalidateStatLine(db, c, *old); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := invalidateStatLine(db, c, stat); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stat)
+	}
+}
+
+// DeleteStatHandler godoc
+// @Summary Delete a game stat
+// @Description Delete a stat line together with its period breakdown
+// @Tags stats
+// @Param id path int true "Stat line id"
+// @Success 204
+// @Failure 400 {string} string "Invalid stat ID"
+// @Failure 404 {string} string "Stat line not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /stats/{id} [delete]
+func DeleteStatHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		statID, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid stat ID", http.StatusBadRequest)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		stat, err := scanStat(tx.QueryRow(`DELETE FROM stats WHERE id = $1 RETURNING `+statColumns, statID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Stat line not found", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := invalidateStatLine(db, c, stat); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// getStat reads a stat line with its periods, locking the line for the rest
+// of the transaction
+func getStat(tx *sql.Tx, statID int) (*models.GameStat, error) {
+	stat, err := scanStat(tx.QueryRow(`SELECT `+statColumns+` FROM stats WHERE stats.id = $1 FOR UPDATE`, statID))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT period, points, rebounds, offensive_rebounds, defensive_rebounds, assists, steals, blocks, fouls, turnovers,
+			field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+			free_throws_made, free_throws_attempted, minutes_played, plus_minus
+		FROM stat_periods WHERE stat_id = $1 ORDER BY period`, statID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p models.PeriodStat
+		err := rows.Scan(&p.Period, &p.Points, &p.Rebounds, &p.OffensiveRebounds, &p.DefensiveRebounds,
+			&p.Assists, &p.Steals, &p.Blocks, &p.Fouls, &p.Turnovers,
+			&p.FieldGoalsMade, &p.FieldGoalsAttempted, &p.ThreePointersMade, &p.ThreePointersAttempted,
+			&p.FreeThrowsMade, &p.FreeThrowsAttempted, &p.MinutesPlayed, &p.PlusMinus)
+		if err != nil {
+			return nil, err
+		}
+		stat.Periods = append(stat.Periods, p)
+	}
+	return &stat, rows.Err()
+}
+
+// updateStat writes every field of the stat line and replaces its periods
+func updateStat(tx *sql.Tx, stat models.GameStat) error {
+	query := `UPDATE stats
+              SET player_id = $2, game_id = $3, points = $4, rebounds = $5, offensive_rebounds = $6, defensive_rebounds = $7,
+                  assists = $8, steals = $9, blocks = $10, fouls = $11, turnovers = $12,
+                  field_goals_made = $13, field_goals_attempted = $14, three_pointers_made = $15, three_pointers_attempted = $16,
+                  free_throws_made = $17, free_throws_attempted = $18, minutes_played = $19, plus_minus = $20,
+                  started = $21, game_date = $22
+              WHERE id = $1`
+	_, err := tx.Exec(query, stat.ID, stat.PlayerID, stat.GameID, stat.Points, stat.Rebounds, stat.OffensiveRebounds, stat.DefensiveRebounds,
+		stat.Assists, stat.Steals, stat.Blocks, stat.Fouls, stat.Turnovers,
+		stat.FieldGoalsMade, stat.FieldGoalsAttempted, stat.ThreePointersMade, stat.ThreePointersAttempted,
+		stat.FreeThrowsMade, stat.FreeThrowsAttempted, stat.MinutesPlayed, stat.PlusMinus, stat.Started, stat.GameDate)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM stat_periods WHERE stat_id = $1`, stat.ID); err != nil {
+		return err
+	}
+	return insertPeriods(tx, stat.ID, stat.Periods)
+}
+
+// hasBoxColumn reports whether a request body gives any game total
+func hasBoxColumn(fields map[string]json.RawMessage) bool {
+	for _, c := range boxColumns(models.BoxScore{}) {
+		if _, ok := fields[c.field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidateStatLine drops the cached averages a stat line counts towards:
+// its player's and those of the team the player was on at the time.
+func invalidateStatLine(db *sql.DB, c cache.Cache, stat models.GameStat) error {
+	tags := []string{playerTag(stat.PlayerID)}
+	teamID, err := teamOnDate(db, stat.PlayerID, stat.GameDate)
+	if err != nil {
+		return err
+	}
+	if teamID != 0 {
+		tags = append(tags, teamTag(teamID))
+	}
+	return c.Invalidate(tags...)
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// AddTeamHandler godoc
+// @Summary Add a new team
+// @Description Add a new team to the database
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param team body models.Team true "Team"
+// @Success 201 {object} models.Team
+// @Failure 400 {string} string "Bad request"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /teams [post]
+func AddTeamHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var team models.Team
+		if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validateTeam(team)
+		leagueErrs, err := resolveLeague(db, &team)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, leagueErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		if err := insertTeam(db, &team); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		team.Players = nil
+		// every team has a row in the standings
+		if err := c.Invalidate(standingsTag); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(team)
+	}
+}
+
+// ListTeamsHandler godoc
+// @Summary List all teams
+// @Description Get a list of all teams
+// @Tags teams
+// @Produce json
+// @Success 200 {array} models.Team
+// @Failure 500 {string} string "Internal server error"
+// @Router /teams [get]
+func ListTeamsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rows, err := db.Query(`SELECT id, name, city, COALESCE(division_id, 0), COALESCE(league_id, 0) FROM teams ORDER BY id`)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		teams := []models.Team{}
+		for rows.Next() {
+			var team models.Team
+			if err := rows.Scan(&team.ID, &team.Name, &team.City, &team.DivisionID, &team.LeagueID); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			teams = append(teams, team)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(teams)
+	}
+}
+
+// GetTeamHandler godoc
+// @Summary Get a team
+// @Description Get a team together with its current roster
+// @Tags teams
+// @Produce json
+// @Param teamId path int true "teamId"
+// @Success 200 {object} models.Team
+// @Failure 400 {string} string "Invalid team ID"
+// @Failure 404 {string} string "Team not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /teams/{teamId} [get]
+func GetTeamHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		teamID, err := strconv.Atoi(mux.Vars(r)["teamId"])
+		if err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+
+		var team models.Team
+		query := `SELECT id, name, city, COALESCE(division_id, 0), COALESCE(league_id, 0) FROM teams WHERE id = $1`
+		err = db.QueryRow(query, teamID).Scan(&team.ID, &team.Name, &team.City, &team.DivisionID, &team.LeagueID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Team not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		rows, err := db.Query(`SELECT `+playerColumns+` FROM players WHERE team_id = $1 ORDER BY name`, teamID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		team.Players = []models.Player{}
+		for rows.Next() {
+			player, err := scanPlayer(rows)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			team.Players = append(team.Players, player)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(team)
+	}
+}
+
+// UpdateTeamHandler godoc
+// @Summary Update a team
+// @Description Update the name, city and division of a team
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param teamId path int true "teamId"
+// @Param team body models.Team true "Team"
+// @Success 200 {object} models.Team
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Team not found"
+// @Failure 422 {object} models.ValidationError "Validation failed"
+// @Failure 500 {string} string "Internal server error"
+// @Router /teams/{teamId} [put]
+func UpdateTeamHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		teamID, err := strconv.Atoi(mux.Vars(r)["teamId"])
+		if err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+
+		var team models.Team
+		if err := json.NewDecoder(r.Body).Decode(&team); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validateTeam(team)
+		leagueErrs, err := resolveLeague(db, &team)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, leagueErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+		team.ID = teamID
+		team.Players = nil
+
+		found, err := updateTeam(db, team)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			http.Error(w, "Team not found", http.StatusNotFound)
+			return
+		}
+		// the team may have moved division or league
+		if err := c.Invalidate(standingsTag, teamTag(team.ID)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(team)
+	}
+}
+
+// DeleteTeamHandler godoc
+// @Summary Delete a team
+// @Description Delete a team that has no current or past players and no games
+// @Tags teams
+// @Param teamId path int true "teamId"
+// @Success 204
+// @Failure 400 {string} string "Invalid team ID"
+// @Failure 404 {string} string "Team not found"
+// @Failure 409 {string} string "Team is still referenced"
+// @Failure 500 {string} string "Internal server error"
+// @Router /teams/{teamId} [delete]
+func DeleteTeamHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		teamID, err := strconv.Atoi(mux.Vars(r)["teamId"])
+		if err != nil {
+			http.Error(w, "Invalid team ID", http.StatusBadRequest)
+			return
+		}
+
+		var hasPlayers, hasHistory, hasGames bool
+		query := `SELECT
+                      EXISTS (SELECT 1 FROM players WHERE team_id = $1),
+                      EXISTS (SELECT 1 FROM player_teams WHERE team_id = $1),
+                      EXISTS (SELECT 1 FROM games WHERE home_team_id = $1 OR away_team_id = $1)`
+		if err := db.QueryRow(query, teamID).Scan(&hasPlayers, &hasHistory, &hasGames); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if hasPlayers {
+			http.Error(w, "Team still has players on its roster", http.StatusConflict)
+			return
+		}
+		if hasHistory {
+			http.Error(w, "Team has roster history", http.StatusConflict)
+			return
+		}
+		if hasGames {
+			http.Error(w, "Team has games recorded against it", http.StatusConflict)
+			return
+		}
+
+		res, err := db.Exec(`DELETE FROM teams WHERE id = $1`, teamID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			http.Error(w, "Team not found", http.StatusNotFound)
+			return
+		}
+		if err := c.Invalidate(standingsTag, teamTag(teamID)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// insertTeam creates the team and sets its ID
+func insertTeam(ex execer, team *models.Team) error {
+	query := `INSERT INTO teams (name, city, division_id, league_id) VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0)) RETURNING id`
+	return ex.QueryRow(query, team.Name, team.City, team.DivisionID, team.LeagueID).Scan(&team.ID)
+}
+
+// updateTeam replaces the team's fields. found is false if there is no such
+// team.
+func updateTeam(ex execer, team models.Team) (found bool, err error) {
+	query := `UPDATE teams SET name = $2, city = $3, division_id = NULLIF($4, 0), league_id = NULLIF($5, 0) WHERE id = $1`
+	res, err := ex.Exec(query, team.ID, team.Name, team.City, team.DivisionID, team.LeagueID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// resolveLeague takes the team's league from its division when it is left
+// out, and reports an unknown league or division and a league the division
+// does not belong to.
+func resolveLeague(db *sql.DB, team *models.Team) (fieldErrors, error) {
+	var errs fieldErrors
+	if team.DivisionID <= 0 {
+		err := errs.exists(db, "leagues", team.LeagueID, "league_id", "league")
+		return errs, err
+	}
+	leagueID, err := divisionLeague(db, team.DivisionID)
+	if err == sql.ErrNoRows {
+		errs.add("division_id", "exists", "division %d does not exist", team.DivisionID)
+		return errs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if team.LeagueID == 0 {
+		team.LeagueID = leagueID
+	} else if team.LeagueID != leagueID {
+		errs.add("league_id", "division_league", "league_id must be %d, the league of division %d", leagueID, team.DivisionID)
+	}
+	return errs, nil
+}
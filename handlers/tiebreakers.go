@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"database/sql"
+	"math"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"sort"
+)
+
+// SeedsHandler godoc
+// @Summary Conference seeds
+// @Description Get every conference's teams in seed order, with ties broken by the NBA tiebreak procedure. Each seed names the rule that put it ahead of the next team when they finished with the same record. Defaults to the current regular season.
+// @Tags standings
+// @Produce json
+// @Param season query int false "Season year, e.g. 2024 for 2023-24"
+// @Param season_type query string false "preseason, regular, play-in or playoffs (default regular)"
+// @Success 200 {object} models.SeedList
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Season not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /standings/seeds [get]
+func SeedsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return seasonTableHandler(db, c, ":seeds", func(t *seasonTable) interface{} { return t.seeds() })
+}
+
+// tiebreakCriterion is one NBA tiebreak rule. value scores a team within the
+// tied group, higher being better, and applies reports whether the rule is
+// used for the group at all.
+type tiebreakCriterion struct {
+	name    string
+	applies func(group []int) bool
+	value   func(team int, group []int) float64
+}
+
+// seedEntry is a team in seed order with the rule that put it ahead of the
+// team after it, if they were tied.
+type seedEntry struct {
+	team int
+	rule string
+}
+
+// tiebreaker orders teams with equal records using the NBA tiebreak
+// procedure, working only from the final games of a season.
+type tiebreaker struct {
+	table    *seasonTable
+	records  map[int]*models.TeamRecord
+	leaders  map[int]bool // division leaders
+	eligible map[int]bool // teams in the top ten of their conference by record
+}
+
+func newTiebreaker(table *seasonTable, records map[int]*models.TeamRecord) *tiebreaker {
+	tb := &tiebreaker{table: table, records: records, leaders: map[int]bool{}, eligible: map[int]bool{}}
+
+	divisions := map[int][]int{}
+	conferences := map[int][]int{}
+	for id, team := range table.teams {
+		if team.DivisionID != 0 {
+			divisions[team.DivisionID] = append(divisions[team.DivisionID], id)
+		}
+		conferences[team.ConferenceID] = append(conferences[team.ConferenceID], id)
+	}
+
+	for _, teams := range conferences {
+		pcts := make([]float64, len(teams))
+		for i, id := range teams {
+			pcts[i] = tb.winPct(id)
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(pcts)))
+		cutoff := pcts[len(pcts)-1]
+		if len(pcts) > 10 {
+			cutoff = pcts[9]
+		}
+		for _, id := range teams {
+			tb.eligible[id] = tb.winPct(id) >= cutoff
+		}
+	}
+
+	// A division leader is decided by the same procedure, minus the
+	// division leader rule itself. It uses the playoff-eligible teams, so
+	// they are found first.
+	for _, teams := range divisions {
+		top := partition(teams, tb.winPct)[0]
+		tb.leaders[tb.resolve(top, false)[0].team] = true
+	}
+	return tb
+}
+
+func (tb *tiebreaker) winPct(team int) float64 {
+	return tb.records[team].WinPct
+}
+
+// pctAgainst is the team's win percentage in games against opponents
+// accepted by include.
+func (tb *tiebreaker) pctAgainst(team int, include func(opponent int) bool) float64 {
+	var wins, losses int
+	for _, g := range tb.table.games {
+		var opponent int
+		var won bool
+		switch team {
+		case g.HomeTeamID:
+			opponent, won = g.AwayTeamID, g.HomeScore > g.AwayScore
+		case g.AwayTeamID:
+			opponent, won = g.HomeTeamID, g.AwayScore > g.HomeScore
+		default:
+			continue
+		}
+		if g.HomeScore == g.AwayScore || !include(opponent) {
+			continue
+		}
+		if won {
+			wins++
+		} else {
+			losses++
+		}
+	}
+	return winPct(wins, losses)
+}
+
+// criteria returns the rules in the order the NBA applies them to a tie
+// between two teams or between more than two.
+func (tb *tiebreaker) criteria(twoTeams, useLeaders bool) []tiebreakCriterion {
+	teams := tb.table.teams
+	headToHead := tiebreakCriterion{
+		name: "head-to-head",
+		value: func(team int, group []int) float64 {
+			return tb.pctAgainst(team, func(opp int) bool { return contains(group, opp) })
+		},
+	}
+	divisionLeader := tiebreakCriterion{
+		name:    "division leader",
+		applies: func([]int) bool { return useLeaders },
+		value: func(team int, _ []int) float64 {
+			if tb.leaders[team] {
+				return 1
+			}
+			return 0
+		},
+	}
+	divisionRecord := tiebreakCriterion{
+		name: "division record",
+		applies: func(group []int) bool {
+			for _, id := range group {
+				if teams[id].DivisionID == 0 || teams[id].DivisionID != teams[group[0]].DivisionID {
+					return false
+				}
+			}
+			return true
+		},
+		value: func(team int, _ []int) float64 {
+			return tb.pctAgainst(team, func(opp int) bool { return teams[opp].DivisionID == teams[team].DivisionID })
+		},
+	}
+	conferenceRecord := tiebreakCriterion{
+		name: "conference record",
+		value: func(team int, _ []int) float64 {
+			return tb.pctAgainst(team, func(opp int) bool { return teams[opp].ConferenceID == teams[team].ConferenceID })
+		},
+	}
+	vsEligibleOwn := tiebreakCriterion{
+		name: "record vs playoff-eligible teams in own conference",
+		value: func(team int, _ []int) float64 {
+			return tb.pctAgainst(team, func(opp int) bool {
+				return tb.eligible[opp] && teams[opp].ConferenceID == teams[team].ConferenceID
+			})
+		},
+	}
+	vsEligibleOther := tiebreakCriterion{
+		name: "record vs playoff-eligible teams in other conference",
+		value: func(team int, _ []int) float64 {
+			return tb.pctAgainst(team, func(opp int) bool {
+				return tb.eligible[opp] && teams[opp].ConferenceID != teams[team].ConferenceID
+			})
+		},
+	}
+	pointDifferential := tiebreakCriterion{
+		name: "point differential",
+		value: func(team int, _ []int) float64 {
+			return float64(tb.records[team].PointsFor - tb.records[team].PointsAgainst)
+		},
+	}
+
+	if twoTeams {
+		return []tiebreakCriterion{headToHead, divisionLeader, divisionRecord, conferenceRecord,
+			vsEligibleOwn, vsEligibleOther, pointDifferential}
+	}
+	// the multi-team procedure has no step against the other conference
+	return []tiebreakCriterion{divisionLeader, headToHead, divisionRecord, conferenceRecord,
+		vsEligibleOwn, pointDifferential}
+}
+
+// resolve orders a group of teams with the same record. The first rule that
+// splits the group decides between the resulting subgroups, and each
+// subgroup starts over from the first rule for its own size, as the NBA
+// procedure does once a multi-team tie is partly broken.
+func (tb *tiebreaker) resolve(group []int, useLeaders bool) []seedEntry {
+	if len(group) == 1 {
+		return []seedEntry{{team: group[0]}}
+	}
+	for _, c := range tb.criteria(len(group) == 2, useLeaders) {
+		if c.applies != nil && !c.applies(group) {
+			continue
+		}
+		buckets := partition(group, func(team int) float64 { return c.value(team, group) })
+		if len(buckets) == 1 {
+			continue
+		}
+		var out []seedEntry
+		for i, bucket := range buckets {
+			sub := tb.resolve(bucket, useLeaders)
+			if i < len(buckets)-1 {
+				sub[len(sub)-1].rule = c.name
+			}
+			out = append(out, sub...)
+		}
+		return out
+	}
+
+	// Nothing separates the teams, so the league would draw lots. Team id
+	// keeps the draw stable between requests.
+	sorted := append([]int(nil), group...)
+	sort.Ints(sorted)
+	out := make([]seedEntry, len(sorted))
+	for i, team := range sorted {
+		out[i] = seedEntry{team: team}
+		if i < len(sorted)-1 {
+			out[i].rule = "drawing of lots"
+		}
+	}
+	return out
+}
+
+// seed orders the teams of a conference: by win percentage, with every
+// group of equal records broken by resolve.
+func (tb *tiebreaker) seed(teams []int) []seedEntry {
+	var out []seedEntry
+	for _, bucket := range partition(teams, tb.winPct) {
+		out = append(out, tb.resolve(bucket, true)...)
+	}
+	return out
+}
+
+// seeds returns the seed list of every conference. Teams without a
+// conference are not seeded.
+func (t *seasonTable) seeds() *models.SeedList {
+	records := t.records()
+	tb := newTiebreaker(t, records)
+
+	conferences := map[int][]int{}
+	names := map[int]string{}
+	for id, team := range t.teams {
+		if team.ConferenceID != 0 {
+			conferences[team.ConferenceID] = append(conferences[team.ConferenceID], id)
+			names[team.ConferenceID] = team.ConferenceName
+		}
+	}
+
+	list := &models.SeedList{Season: t.season, Conferences: []models.ConferenceSeeds{}}
+	for confID, teams := range conferences {
+		conf := models.ConferenceSeeds{ConferenceID: confID, Name: names[confID]}
+		entries := tb.seed(teams)
+		var leader models.TeamRecord
+		for i, e := range entries {
+			rec := *records[e.team]
+			if i == 0 {
+				leader = rec
+			}
+			rec.GamesBehind = float64((leader.Wins-rec.Wins)+(rec.Losses-leader.Losses)) / 2
+			conf.Seeds = append(conf.Seeds, models.Seed{Seed: i + 1, TeamRecord: rec, Tiebreak: e.rule})
+		}
+		list.Conferences = append(list.Conferences, conf)
+	}
+	sort.Slice(list.Conferences, func(i, j int) bool {
+		return list.Conferences[i].ConferenceID < list.Conferences[j].ConferenceID
+	})
+	return list
+}
+
+// partition splits teams into groups of equal value, best group first
+func partition(teams []int, value func(team int) float64) [][]int {
+	sorted := append([]int(nil), teams...)
+	values := map[int]float64{}
+	for _, id := range sorted {
+		values[id] = value(id)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if values[sorted[i]] != values[sorted[j]] {
+			return values[sorted[i]] > values[sorted[j]]
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	var groups [][]int
+	for i, id := range sorted {
+		if i == 0 || math.Abs(values[id]-values[sorted[i-1]]) > 1e-9 {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], id)
+	}
+	return groups
+}
+
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
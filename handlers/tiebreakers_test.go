@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"nba_stats/models"
+	"testing"
+)
+
+// tiedLeadersTable builds a twelve-team conference in which teams 1 and 2
+// tie for the lead of their division on record, head-to-head, division and
+// conference record. Team 1 beat a playoff-eligible conference rival while
+// team 2 only beat team 12, which is not eligible, and team 2 has the better
+// point differential.
+func tiedLeadersTable() (*seasonTable, map[int]*models.TeamRecord) {
+	table := &seasonTable{teams: map[int]standingsTeam{}}
+	records := map[int]*models.TeamRecord{}
+	for id := 1; id <= 12; id++ {
+		division := 20
+		pct := 0.5
+		switch {
+		case id <= 2:
+			division, pct = 10, 0.6
+		case id >= 11:
+			pct = 0.1
+		}
+		table.teams[id] = standingsTeam{ID: id, DivisionID: division, ConferenceID: 1}
+		records[id] = &models.TeamRecord{TeamID: id, WinPct: pct}
+	}
+	records[2].PointsFor = 100
+
+	win := func(winner, loser int) models.Game {
+		return models.Game{HomeTeamID: winner, AwayTeamID: loser, HomeScore: 100, AwayScore: 90, Status: "final"}
+	}
+	table.games = []models.Game{
+		win(1, 2), win(2, 1),
+		win(1, 3), win(11, 1),
+		win(4, 2), win(2, 12),
+	}
+	return table, records
+}
+
+func TestTiebreakerDivisionLeader(t *testing.T) {
+	table, records := tiedLeadersTable()
+	tb := newTiebreaker(table, records)
+
+	tests := []struct {
+		team     int
+		eligible bool
+		leader   bool
+	}{
+		{1, true, true},
+		{2, true, false},
+		{3, true, false},
+		{11, false, false},
+	}
+	for _, tt := range tests {
+		if got := tb.eligible[tt.team]; got != tt.eligible {
+			t.Errorf("eligible[%d] = %v, want %v", tt.team, got, tt.eligible)
+		}
+		if got := tb.leaders[tt.team]; got != tt.leader {
+			t.Errorf("leaders[%d] = %v, want %v", tt.team, got, tt.leader)
+		}
+	}
+
+	entries := tb.resolve([]int{1, 2}, false)
+	if entries[0].team != 1 || entries[0].rule != "record vs playoff-eligible teams in own conference" {
+		t.Errorf("resolve(1, 2) = %+v, want team 1 ahead on record vs playoff-eligible teams", entries)
+	}
+}
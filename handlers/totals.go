@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"database/sql"
+	"nba_stats/models"
+)
+
+// statTotals are the summed columns of a set of stat lines. Averages and
+// shooting percentages are derived from the sums so that percentages are
+// ratio-of-sums rather than the mean of per-game ratios.
+type statTotals struct {
+	Games                  int
+	GamesStarted           int
+	Points                 float64
+	Rebounds               float64
+	OffensiveRebounds      float64
+	DefensiveRebounds      float64
+	Assists                float64
+	Steals                 float64
+	Blocks                 float64
+	Fouls                  float64
+	Turnovers              float64
+	FieldGoalsMade         float64
+	FieldGoalsAttempted    float64
+	ThreePointersMade      float64
+	ThreePointersAttempted float64
+	FreeThrowsMade         float64
+	FreeThrowsAttempted    float64
+	MinutesPlayed          float64
+	PlusMinus              float64
+}
+
+// statTotalsColumns is the select list scanned by scanTotals. It expects the
+// stat lines to be reachable as "stats".
+const statTotalsColumns = `
+	COUNT(*),
+	COUNT(*) FILTER (WHERE stats.started),
+	COALESCE(SUM(stats.points), 0),
+	COALESCE(SUM(stats.rebounds), 0),
+	COALESCE(SUM(stats.offensive_rebounds), 0),
+	COALESCE(SUM(stats.defensive_rebounds), 0),
+	COALESCE(SUM(stats.assists), 0),
+	COALESCE(SUM(stats.steals), 0),
+	COALESCE(SUM(stats.blocks), 0),
+	COALESCE(SUM(stats.fouls), 0),
+	COALESCE(SUM(stats.turnovers), 0),
+	COALESCE(SUM(stats.field_goals_made), 0),
+	COALESCE(SUM(stats.field_goals_attempted), 0),
+	COALESCE(SUM(stats.three_pointers_made), 0),
+	COALESCE(SUM(stats.three_pointers_attempted), 0),
+	COALESCE(SUM(stats.free_throws_made), 0),
+	COALESCE(SUM(stats.free_throws_attempted), 0),
+	COALESCE(SUM(stats.minutes_played), 0),
+	COALESCE(SUM(stats.plus_minus), 0)`
+
+// rollupTotalsColumns is statTotalsColumns for rows of stat_rollups, which
+// hold sums and counts already
+const rollupTotalsColumns = `
+	COALESCE(SUM(stat_rollups.games), 0),
+	COALESCE(SUM(stat_rollups.games_started), 0),
+	COALESCE(SUM(stat_rollups.points), 0),
+	COALESCE(SUM(stat_rollups.rebounds), 0),
+	COALESCE(SUM(stat_rollups.offensive_rebounds), 0),
+	COALESCE(SUM(stat_rollups.defensive_rebounds), 0),
+	COALESCE(SUM(stat_rollups.assists), 0),
+	COALESCE(SUM(stat_rollups.steals), 0),
+	COALESCE(SUM(stat_rollups.blocks), 0),
+	COALESCE(SUM(stat_rollups.fouls), 0),
+	COALESCE(SUM(stat_rollups.turnovers), 0),
+	COALESCE(SUM(stat_rollups.field_goals_made), 0),
+	COALESCE(SUM(stat_rollups.field_goals_attempted), 0),
+	COALESCE(SUM(stat_rollups.three_pointers_made), 0),
+	COALESCE(SUM(stat_rollups.three_pointers_attempted), 0),
+	COALESCE(SUM(stat_rollups.free_throws_made), 0),
+	COALESCE(SUM(stat_rollups.free_throws_attempted), 0),
+	COALESCE(SUM(stat_rollups.minutes_played), 0),
+	COALESCE(SUM(stat_rollups.plus_minus), 0)`
+
+// scanTotals scans a row selected with statTotalsColumns, followed by any
+// columns scanned into extra. It returns sql.ErrNoRows when the row
+// aggregates no stat lines.
+func scanTotals(row scanner, extra ...interface{}) (*statTotals, error) {
+	var t statTotals
+	dest := []interface{}{&t.Games, &t.GamesStarted, &t.Points, &t.Rebounds, &t.OffensiveRebounds, &t.DefensiveRebounds,
+		&t.Assists, &t.Steals, &t.Blocks, &t.Fouls, &t.Turnovers,
+		&t.FieldGoalsMade, &t.FieldGoalsAttempted, &t.ThreePointersMade, &t.ThreePointersAttempted,
+		&t.FreeThrowsMade, &t.FreeThrowsAttempted, &t.MinutesPlayed, &t.PlusMinus}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if t.Games == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &t, nil
+}
+
+// add adds o's totals to t
+func (t *statTotals) add(o statTotals) {
+	t.Games += o.Games
+	t.GamesStarted += o.GamesStarted
+	t.Points += o.Points
+	t.Rebounds += o.Rebounds
+	t.OffensiveRebounds += o.OffensiveRebounds
+	t.DefensiveRebounds += o.DefensiveRebounds
+	t.Assists += o.Assists
+	t.Steals += o.Steals
+	t.Blocks += o.Blocks
+	t.Fouls += o.Fouls
+	t.Turnovers += o.Turnovers
+	t.FieldGoalsMade += o.FieldGoalsMade
+	t.FieldGoalsAttempted += o.FieldGoalsAttempted
+	t.ThreePointersMade += o.ThreePointersMade
+	t.ThreePointersAttempted += o.ThreePointersAttempted
+	t.FreeThrowsMade += o.FreeThrowsMade
+	t.FreeThrowsAttempted += o.FreeThrowsAttempted
+	t.MinutesPlayed += o.MinutesPlayed
+	t.PlusMinus += o.PlusMinus
+}
+
+// averages returns the per-game averages of the totals.
+func (t statTotals) averages() *models.AvgStat {
+	return t.scaled(float64(t.Games))
+}
+
+// scaled returns the totals divided by divisor, or zeros when it is 0.
+// Percentages are not scaled.
+func (t statTotals) scaled(divisor float64) *models.AvgStat {
+	return &models.AvgStat{
+		AvgPoints:                 ratio(t.Points, divisor),
+		AvgRebounds:               ratio(t.Rebounds, divisor),
+		AvgOffensiveRebounds:      ratio(t.OffensiveRebounds, divisor),
+		AvgDefensiveRebounds:      ratio(t.DefensiveRebounds, divisor),
+		AvgAssists:                ratio(t.Assists, divisor),
+		AvgSteals:                 ratio(t.Steals, divisor),
+		AvgBlocks:                 ratio(t.Blocks, divisor),
+		AvgFouls:                  ratio(t.Fouls, divisor),
+		AvgTurnovers:              ratio(t.Turnovers, divisor),
+		AvgFieldGoalsMade:         ratio(t.FieldGoalsMade, divisor),
+		AvgFieldGoalsAttempted:    ratio(t.FieldGoalsAttempted, divisor),
+		AvgThreePointersMade:      ratio(t.ThreePointersMade, divisor),
+		AvgThreePointersAttempted: ratio(t.ThreePointersAttempted, divisor),
+		AvgFreeThrowsMade:         ratio(t.FreeThrowsMade, divisor),
+		AvgFreeThrowsAttempted:    ratio(t.FreeThrowsAttempted, divisor),
+		AvgMinutesPlayed:          ratio(t.MinutesPlayed, divisor),
+		AvgPlusMinus:              ratio(t.PlusMinus, divisor),
+		GamesPlayed:               t.Games,
+		GamesStarted:              t.GamesStarted,
+		FieldGoalPct:              ratio(t.FieldGoalsMade, t.FieldGoalsAttempted),
+		ThreePointPct:             ratio(t.ThreePointersMade, t.ThreePointersAttempted),
+		FreeThrowPct:              ratio(t.FreeThrowsMade, t.FreeThrowsAttempted),
+	}
+}
+
+// ratio returns made/attempted, or 0 when nothing was attempted.
+func ratio(made, attempted float64) float64 {
+	if attempted == 0 {
+		return 0
+	}
+	return made / attempted
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"nba_stats/cache"
+	"nba_stats/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// AddTransactionHandler godoc
+// @Summary Move a player to another team
+// @Description Close the player's current stint on effective_date and open one with to_team_id. A to_team_id of 0 releases the player. A player without a team can only be signed on or after the end of the last stint.
+// @Tags players
+// @Accept json
+// @Produce json
+// @Param transaction body models.Transaction true "Transaction"
+// @Success 201 {object} models.Transaction
+// @Failure 400 {string} string "Bad request"
+// @Failure 404 {string} string "Player not found"
+// @Failure 422 {object} models.ValidationError "Validation failed, including a move to the player's own team, a release of a player without a team and an effective_date that overlaps an earlier stint"
+// @Failure 500 {string} string "Internal server error"
+// @Router /transactions [post]
+func AddTransactionHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var t models.Transaction
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		errs := validateTransaction(t)
+		refErrs, err := checkTransactionRefs(db, t)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if errs = append(errs, refErrs...); len(errs) > 0 {
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer tx.Rollback()
+
+		var exists bool
+		err = tx.QueryRow(`SELECT true FROM players WHERE id = $1 FOR UPDATE`, t.PlayerID).Scan(&exists)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				http.Error(w, "Player not found", http.StatusNotFound)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		var stintID int
+		var startDate sql.NullTime
+		query := `SELECT id, team_id, start_date FROM player_teams WHERE player_id = $1 AND end_date IS NULL`
+		err = tx.QueryRow(query, t.PlayerID).Scan(&stintID, &t.FromTeamID, &startDate)
+		if err != nil && err != sql.ErrNoRows {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			if t.FromTeamID == t.ToTeamID {
+				errs.add("to_team_id", "different", "player %d is already on team %d", t.PlayerID, t.ToTeamID)
+			}
+			if startDate.Valid && !t.EffectiveDate.After(startDate.Time) {
+				errs.add("effective_date", "after_stint", "effective_date must be after the start of the current stint on %s",
+					startDate.Time.Format(dateLayout))
+			}
+			if len(errs) > 0 {
+				writeValidationErrors(w, errs)
+				return
+			}
+			if _, err := tx.Exec(`UPDATE player_teams SET end_date = $2 WHERE id = $1`, stintID, t.EffectiveDate); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		} else if t.ToTeamID == 0 {
+			errs.add("to_team_id", "on_team", "player %d is not on a team to be released from", t.PlayerID)
+			writeValidationErrors(w, errs)
+			return
+		} else {
+			// a stint must not overlap the player's earlier ones, or the
+			// lines in the overlap would count for two teams
+			var lastEnd sql.NullTime
+			if err := tx.QueryRow(`SELECT MAX(end_date) FROM player_teams WHERE player_id = $1`, t.PlayerID).Scan(&lastEnd); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if lastEnd.Valid && t.EffectiveDate.Before(lastEnd.Time) {
+				errs.add("effective_date", "after_stint", "effective_date must not be before the end of the previous stint on %s",
+					lastEnd.Time.Format(dateLayout))
+				writeValidationErrors(w, errs)
+				return
+			}
+		}
+
+		if t.ToTeamID != 0 {
+			query = `INSERT INTO player_teams (player_id, team_id, start_date) VALUES ($1, $2, $3)`
+			if _, err := tx.Exec(query, t.PlayerID, t.ToTeamID, t.EffectiveDate); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if _, err := tx.Exec(`UPDATE players SET team_id = NULLIF($2, 0) WHERE id = $1`, t.PlayerID, t.ToTeamID); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// games on or after the effective date may already be recorded
+		if err := c.Invalidate(teamTag(t.FromTeamID), teamTag(t.ToTeamID)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+	}
+}
+
+// ListPlayerStintsHandler godoc
+// @Summary Player team history
+// @Description Get every stint the player has had with a team, oldest first
+// @Tags players
+// @Produce json
+// @Param playerId path int true "PlayerId"
+// @Success 200 {array} models.PlayerStint
+// @Failure 400 {string} string "Invalid player ID"
+// @Failure 500 {string} string "Internal server error"
+// @Router /players/{playerId}/teams [get]
+func ListPlayerStintsHandler(db *sql.DB, c cache.Cache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		playerID, err := strconv.Atoi(mux.Vars(r)["playerId"])
+		if err != nil {
+			http.Error(w, "Invalid player ID", http.StatusBadRequest)
+			return
+		}
+
+		query := `SELECT id, player_id, team_id, start_date, end_date FROM player_teams
+                  WHERE player_id = $1 ORDER BY start_date NULLS FIRST`
+		rows, err := db.Query(query, playerID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer rows.Close()
+
+		stints := []models.PlayerStint{}
+		for rows.Next() {
+			var stint models.PlayerStint
+			var start, end sql.NullTime
+			if err := rows.Scan(&stint.ID, &stint.PlayerID, &stint.TeamID, &start, &end); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if start.Valid {
+				stint.StartDate = &start.Time
+			}
+			if end.Valid {
+				stint.EndDate = &end.Time
+			}
+			stints = append(stints, stint)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stints)
+	}
+}
+
+// teamOnDate returns the team the player was on at date, or 0 if the player
+// had no stint covering it.
+func teamOnDate(db *sql.DB, playerID int, date time.Time) (int, error) {
+	query := `SELECT team_id FROM player_teams
+              WHERE player_id = $1
+                AND (start_date IS NULL OR start_date <= $2)
+                AND (end_date IS NULL OR end_date > $2)`
+	var teamID int
+	err := db.QueryRow(query, playerID, date).Scan(&teamID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return teamID, err
+}
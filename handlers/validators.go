@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"nba_stats/models"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var positions = map[string]bool{
+	"PG": true, "SG": true, "SF": true, "PF": true, "C": true,
+	"G": true, "F": true, "G-F": true, "F-G": true, "F-C": true, "C-F": true,
+}
+
+var gameStatuses = map[string]bool{
+	"scheduled":   true,
+	"in_progress": true,
+	"final":       true,
+	"postponed":   true,
+}
+
+var countryCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// fieldErrors collects the failures of a request body. Handlers validate the
+// whole body before touching the database and report every failure at once.
+type fieldErrors []models.FieldError
+
+func (e *fieldErrors) add(field, rule, format string, args ...interface{}) {
+	*e = append(*e, models.FieldError{Field: field, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+func (e *fieldErrors) required(ok bool, field string) {
+	if !ok {
+		e.add(field, "required", "%s is required", field)
+	}
+}
+
+func (e *fieldErrors) min(value float64, min float64, field string) {
+	if value < min {
+		e.add(field, "min", "%s must be at least %g", field, min)
+	}
+}
+
+func (e *fieldErrors) positive(value float64, field string) {
+	if value <= 0 {
+		e.add(field, "positive", "%s must be positive", field)
+	}
+}
+
+func (e *fieldErrors) between(value, min, max float64, field string) {
+	if value < min || value > max {
+		e.add(field, "range", "%s must be between %g and %g", field, min, max)
+	}
+}
+
+// notAbove checks that a count does not exceed the count it is part of,
+// e.g. makes against attempts.
+func (e *fieldErrors) notAbove(value, limit int, field, limitField string) {
+	if value > limit {
+		e.add(field, "lte_field", "%s must not exceed %s", field, limitField)
+	}
+}
+
+// exists checks that id, when given, names a row of table. Referenced rows
+// are checked before writing so that an unknown id is reported as a field
+// error rather than as a foreign key violation.
+func (e *fieldErrors) exists(ex execer, table string, id int, field, entity string) error {
+	if id <= 0 {
+		return nil
+	}
+	var found bool
+	err := ex.QueryRow(`SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&found)
+	if err == nil && !found {
+		e.add(field, "exists", "%s %d does not exist", entity, id)
+	}
+	return err
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a duplicate,
+// for writes that race with a concurrent one past their duplicate check
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "23505"
+}
+
+// writeValidationErrors responds 422 with every failed field
+func writeValidationErrors(w http.ResponseWriter, errs fieldErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(models.ValidationError{Errors: errs})
+}
+
+func validatePlayer(p models.Player) fieldErrors {
+	var errs fieldErrors
+	errs.required(p.Name != "", "name")
+	errs.min(float64(p.TeamID), 0, "team_id")
+	if p.Position != "" && !positions[p.Position] {
+		errs.add("position", "oneof", "position must be one of PG, SG, SF, PF, C, G, F, G-F, F-G, F-C, C-F")
+	}
+	if p.JerseyNumber != nil {
+		errs.between(float64(*p.JerseyNumber), 0, 99, "jersey_number")
+	}
+	if p.HeightCm != nil {
+		errs.between(float64(*p.HeightCm), 150, 250, "height_cm")
+	}
+	if p.WeightKg != nil {
+		errs.between(float64(*p.WeightKg), 50, 200, "weight_kg")
+	}
+	if p.BirthDate != nil && p.BirthDate.After(time.Now()) {
+		errs.add("birth_date", "past", "birth_date must not be in the future")
+	}
+	if p.Nationality != "" && !countryCode.MatchString(p.Nationality) {
+		errs.add("nationality", "format", "nationality must be an ISO 3166-1 alpha-3 country code")
+	}
+	if p.DraftYear != nil {
+		errs.between(float64(*p.DraftYear), 1947, float64(time.Now().Year()), "draft_year")
+	}
+	return errs
+}
+
+// boxColumn is one field of a box score under its JSON name
+type boxColumn struct {
+	field string
+	value float64
+}
+
+func boxColumns(b models.BoxScore) []boxColumn {
+	return []boxColumn{
+		{"points", float64(b.Points)},
+		{"rebounds", float64(b.Rebounds)},
+		{"offensive_rebounds", float64(b.OffensiveRebounds)},
+		{"defensive_rebounds", float64(b.DefensiveRebounds)},
+		{"assists", float64(b.Assists)},
+		{"steals", float64(b.Steals)},
+		{"blocks", float64(b.Blocks)},
+		{"fouls", float64(b.Fouls)},
+		{"turnovers", float64(b.Turnovers)},
+		{"field_goals_made", float64(b.FieldGoalsMade)},
+		{"field_goals_attempted", float64(b.FieldGoalsAttempted)},
+		{"three_pointers_made", float64(b.ThreePointersMade)},
+		{"three_pointers_attempted", float64(b.ThreePointersAttempted)},
+		{"free_throws_made", float64(b.FreeThrowsMade)},
+		{"free_throws_attempted", float64(b.FreeThrowsAttempted)},
+		{"minutes_played", b.MinutesPlayed},
+		{"plus_minus", float64(b.PlusMinus)},
+	}
+}
+
+// validateBoxScore checks the rules every box score follows, naming fields
+// with prefix. Limits that depend on the game, such as minutes, or on whose
+// box score it is, such as the personal foul limit, are left to the caller.
+func validateBoxScore(b models.BoxScore, prefix string) fieldErrors {
+	var errs fieldErrors
+	for _, c := range boxColumns(b) {
+		if c.field != "plus_minus" {
+			errs.min(c.value, 0, prefix+c.field)
+		}
+	}
+
+	if b.Rebounds != b.OffensiveRebounds+b.DefensiveRebounds {
+		errs.add(prefix+"rebounds", "sum", "%srebounds must equal %soffensive_rebounds + %sdefensive_rebounds", prefix, prefix, prefix)
+	}
+	errs.notAbove(b.FieldGoalsMade, b.FieldGoalsAttempted, prefix+"field_goals_made", prefix+"field_goals_attempted")
+	errs.notAbove(b.ThreePointersMade, b.ThreePointersAttempted, prefix+"three_pointers_made", prefix+"three_pointers_attempted")
+	errs.notAbove(b.ThreePointersMade, b.FieldGoalsMade, prefix+"three_pointers_made", prefix+"field_goals_made")
+	errs.notAbove(b.ThreePointersAttempted, b.FieldGoalsAttempted, prefix+"three_pointers_attempted", prefix+"field_goals_attempted")
+	errs.notAbove(b.FreeThrowsMade, b.FreeThrowsAttempted, prefix+"free_throws_made", prefix+"free_throws_attempted")
+	return errs
+}
+
+// validateGameStat checks a stat line and its periods against the game it
+// belongs to and the rules the game is played under. game is nil if game_id
+// did not resolve, in which case the limits that depend on the game are
+// skipped.
+func validateGameStat(gs models.GameStat, game *models.Game, rules models.Rules) fieldErrors {
+	var errs fieldErrors
+	errs.required(gs.PlayerID > 0, "player_id")
+	errs.required(gs.GameID > 0, "game_id")
+	errs = append(errs, validateBoxScore(gs.BoxScore, "")...)
+	if game != nil {
+		errs.between(float64(gs.Fouls), 0, float64(rules.FoulLimit), "fouls")
+		errs.between(gs.MinutesPlayed, 0, rules.GameMinutes(game.OvertimePeriods), "minutes_played")
+	}
+
+	if len(gs.Periods) == 0 {
+		return errs
+	}
+	var sum models.BoxScore
+	seen := map[int]bool{}
+	for i, p := range gs.Periods {
+		prefix := fmt.Sprintf("periods[%d].", i)
+		if seen[p.Period] {
+			errs.add(prefix+"period", "unique", "period %d is listed more than once", p.Period)
+		}
+		seen[p.Period] = true
+		if game != nil {
+			errs.between(float64(p.Period), 1, float64(rules.Periods+game.OvertimePeriods), prefix+"period")
+		} else {
+			errs.min(float64(p.Period), 1, prefix+"period")
+		}
+		errs = append(errs, validateBoxScore(p.BoxScore, prefix)...)
+		if game != nil {
+			errs.between(float64(p.Fouls), 0, float64(rules.FoulLimit), prefix+"fouls")
+			errs.between(p.MinutesPlayed, 0, rules.PeriodLength(p.Period), prefix+"minutes_played")
+		}
+		sum = sum.Add(p.BoxScore)
+	}
+	totals, periods := boxColumns(gs.BoxScore), boxColumns(sum)
+	for i := range totals {
+		if math.Abs(totals[i].value-periods[i].value) > 1e-6 {
+			errs.add(totals[i].field, "period_sum", "%s is %g but the periods add up to %g",
+				totals[i].field, totals[i].value, periods[i].value)
+		}
+	}
+	return errs
+}
+
+// validateTeamGameStat checks a team box score against the game it belongs to
+// and the rules the game is played under
+func validateTeamGameStat(ts models.TeamGameStat, game *models.Game, rules models.Rules) fieldErrors {
+	var errs fieldErrors
+	if ts.TeamID != game.HomeTeamID && ts.TeamID != game.AwayTeamID {
+		errs.add("team_id", "participant", "team %d did not play in game %d", ts.TeamID, game.ID)
+	}
+	errs = append(errs, validateBoxScore(ts.BoxScore, "")...)
+	errs.between(ts.MinutesPlayed, 0, 5*rules.GameMinutes(game.OvertimePeriods), "minutes_played")
+	errs.min(float64(ts.TeamRebounds), 0, "team_rebounds")
+	errs.notAbove(ts.TeamRebounds, ts.Rebounds, "team_rebounds", "rebounds")
+	errs.min(float64(ts.TeamTurnovers), 0, "team_turnovers")
+	errs.notAbove(ts.TeamTurnovers, ts.Turnovers, "team_turnovers", "turnovers")
+	return errs
+}
+
+func validateGame(g models.Game) fieldErrors {
+	var errs fieldErrors
+	errs.required(g.HomeTeamID > 0, "home_team_id")
+	errs.required(g.AwayTeamID > 0, "away_team_id")
+	if g.HomeTeamID != 0 && g.HomeTeamID == g.AwayTeamID {
+		errs.add("away_team_id", "different", "away_team_id must differ from home_team_id")
+	}
+	errs.required(!g.GameDate.IsZero(), "game_date")
+	errs.min(float64(g.HomeScore), 0, "home_score")
+	errs.min(float64(g.AwayScore), 0, "away_score")
+	errs.min(float64(g.OvertimePeriods), 0, "overtime_periods")
+	if !gameStatuses[g.Status] {
+		errs.add("status", "oneof", "status must be one of scheduled, in_progress, final, postponed")
+	}
+	return errs
+}
+
+func validateTeam(t models.Team) fieldErrors {
+	var errs fieldErrors
+	errs.required(t.Name != "", "name")
+	errs.required(t.City != "", "city")
+	errs.min(float64(t.DivisionID), 0, "division_id")
+	errs.min(float64(t.LeagueID), 0, "league_id")
+	return errs
+}
+
+func validateSeason(s models.Season) fieldErrors {
+	var errs fieldErrors
+	errs.required(s.Year > 0, "year")
+	if !seasonTypes[s.Type] {
+		errs.add("type", "oneof", "type must be one of preseason, regular, play-in, playoffs")
+	}
+	errs.required(!s.StartDate.IsZero(), "start_date")
+	errs.required(!s.EndDate.IsZero(), "end_date")
+	if s.EndDate.Before(s.StartDate) {
+		errs.add("end_date", "after_field", "end_date must not be before start_date")
+	}
+	return errs
+}
+
+func validateTransaction(t models.Transaction) fieldErrors {
+	var errs fieldErrors
+	errs.required(t.PlayerID > 0, "player_id")
+	errs.min(float64(t.ToTeamID), 0, "to_team_id")
+	errs.required(!t.EffectiveDate.IsZero(), "effective_date")
+	return errs
+}
+
+func validateLeague(l models.League) fieldErrors {
+	var errs fieldErrors
+	errs.required(l.Name != "", "name")
+	errs.positive(l.PeriodMinutes, "period_minutes")
+	errs.positive(float64(l.Periods), "periods")
+	errs.positive(l.OvertimeMinutes, "overtime_minutes")
+	errs.positive(float64(l.FoulLimit), "foul_limit")
+	return errs
+}
+
+func validateConference(c models.Conference) fieldErrors {
+	var errs fieldErrors
+	errs.required(c.LeagueID > 0, "league_id")
+	errs.required(c.Name != "", "name")
+	return errs
+}
+
+func validateDivision(d models.Division) fieldErrors {
+	var errs fieldErrors
+	errs.required(d.ConferenceID > 0, "conference_id")
+	errs.required(d.Name != "", "name")
+	return errs
+}
+
+// checkPlayerRefs checks the rows a player refers to
+func checkPlayerRefs(ex execer, p models.Player) (fieldErrors, error) {
+	var errs fieldErrors
+	err := errs.exists(ex, "teams", p.TeamID, "team_id", "team")
+	return errs, err
+}
+
+// checkGameStatRefs checks the player a stat line refers to and that the
+// player has no other line in the game. The game itself is checked by the
+// caller, which needs it for the game's limits.
+func checkGameStatRefs(ex execer, gs models.GameStat) (fieldErrors, error) {
+	var errs fieldErrors
+	if err := errs.exists(ex, "players", gs.PlayerID, "player_id", "player"); err != nil {
+		return nil, err
+	}
+	if gs.PlayerID <= 0 || gs.GameID <= 0 {
+		return errs, nil
+	}
+	var duplicate bool
+	err := ex.QueryRow(`SELECT EXISTS (SELECT 1 FROM stats WHERE game_id = $1 AND player_id = $2 AND id <> $3)`,
+		gs.GameID, gs.PlayerID, gs.ID).Scan(&duplicate)
+	if err == nil && duplicate {
+		errs.duplicateStat(gs)
+	}
+	return errs, err
+}
+
+func (e *fieldErrors) duplicateStat(gs models.GameStat) {
+	e.add("player_id", "duplicate", "player %d already has a stat line in game %d", gs.PlayerID, gs.GameID)
+}
+
+// checkGameRefs checks the teams a game refers to
+func checkGameRefs(ex execer, g models.Game) (fieldErrors, error) {
+	var errs fieldErrors
+	if err := errs.exists(ex, "teams", g.HomeTeamID, "home_team_id", "team"); err != nil {
+		return nil, err
+	}
+	err := errs.exists(ex, "teams", g.AwayTeamID, "away_team_id", "team")
+	return errs, err
+}
+
+// checkGameLines checks that the stat lines already recorded for a game
+// still fit it once it is updated from old to game under the rules of its
+// new home team. Minutes and periods depend on the overtime periods and the
+// rules, so they are reported on home_team_id when the home team changes and
+// on overtime_periods otherwise; fouls and period lengths only depend on the
+// rules.
+func checkGameLines(ex execer, old, game models.Game, rules models.Rules) (fieldErrors, error) {
+	var minutes, periodMinutes, overtimeMinutes float64
+	var fouls, periodFouls, lastPeriod int
+	err := ex.QueryRow(`
+		SELECT COALESCE(MAX(stats.minutes_played), 0), COALESCE(MAX(stats.fouls), 0),
+			COALESCE(MAX(stat_periods.period), 0),
+			COALESCE(MAX(stat_periods.minutes_played) FILTER (WHERE stat_periods.period <= $2), 0),
+			COALESCE(MAX(stat_periods.minutes_played) FILTER (WHERE stat_periods.period > $2), 0),
+			COALESCE(MAX(stat_periods.fouls), 0)
+		FROM stats
+		LEFT JOIN stat_periods ON stat_periods.stat_id = stats.id
+		WHERE stats.game_id = $1`, game.ID, rules.Periods).Scan(&minutes, &fouls, &lastPeriod,
+		&periodMinutes, &overtimeMinutes, &periodFouls)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs fieldErrors
+	field := "overtime_periods"
+	if old.HomeTeamID != game.HomeTeamID {
+		field = "home_team_id"
+	}
+	if limit := rules.GameMinutes(game.OvertimePeriods); minutes > limit {
+		errs.add(field, "stat_lines", "a stat line has %g minutes but the game would last %g", minutes, limit)
+	}
+	if periods := rules.Periods + game.OvertimePeriods; lastPeriod > periods {
+		errs.add(field, "stat_lines", "a stat line has period %d but the game would have %d periods", lastPeriod, periods)
+	}
+	if fouls > rules.FoulLimit || periodFouls > rules.FoulLimit {
+		errs.add("home_team_id", "stat_lines", "a stat line has more fouls than the home team's league limit of %d", rules.FoulLimit)
+	}
+	if periodMinutes > rules.PeriodMinutes || overtimeMinutes > rules.OvertimeMinutes {
+		errs.add("home_team_id", "stat_lines", "a stat line has a period longer than the home team's league allows")
+	}
+	return errs, nil
+}
+
+// checkTransactionRefs checks the team a transaction moves a player to. An
+// unknown player is reported as not found by the handler.
+func checkTransactionRefs(ex execer, t models.Transaction) (fieldErrors, error) {
+	var errs fieldErrors
+	err := errs.exists(ex, "teams", t.ToTeamID, "to_team_id", "team")
+	return errs, err
+}
+
+func checkConferenceRefs(ex execer, c models.Conference) (fieldErrors, error) {
+	var errs fieldErrors
+	err := errs.exists(ex, "leagues", c.LeagueID, "league_id", "league")
+	return errs, err
+}
+
+func checkDivisionRefs(ex execer, d models.Division) (fieldErrors, error) {
+	var errs fieldErrors
+	err := errs.exists(ex, "conferences", d.ConferenceID, "conference_id", "conference")
+	return errs, err
+}
@@ -3,8 +3,11 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"nba_stats/cache"
 	_ "nba_stats/docs" // docs is generated by Swag CLI, you have to import it.
 	"nba_stats/handlers"
+	"strconv"
+	"time"
 
 	"log"
 	"net/http"
@@ -27,13 +30,17 @@ func main() {
 	}
 
 	cfg := Config{
-		DBUser:     getEnv("DB_USER", "your_default_db_user"),
-		DBPassword: getEnv("DB_PASSWORD", "your_default_db_password"),
-		DBName:     getEnv("DB_NAME", "your_default_db_name"),
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		RedisHost:  getEnv("REDIS_HOST", "localhost"),
-		RedisPort:  getEnv("REDIS_PORT", "6379"),
+		DBUser:      getEnv("DB_USER", "your_default_db_user"),
+		DBPassword:  getEnv("DB_PASSWORD", "your_default_db_password"),
+		DBName:      getEnv("DB_NAME", "your_default_db_name"),
+		DBHost:      getEnv("DB_HOST", "localhost"),
+		DBPort:      getEnv("DB_PORT", "5432"),
+		RedisHost:   getEnv("REDIS_HOST", "localhost"),
+		RedisPort:   getEnv("REDIS_PORT", "6379"),
+		CacheMode:   getEnv("CACHE_MODE", "tiered"),
+		CacheSize:   getEnv("CACHE_SIZE", "10000"),
+		CacheStale:  getEnv("CACHE_STALE", "0s"),
+		PERInterval: getEnv("PER_INTERVAL", "1m"),
 	}
 
 	db, err := initDB(cfg)
@@ -44,18 +51,73 @@ func main() {
 
 	rdb, err := initRedis(cfg)
 	if err != nil {
-		log.Fatalf("Could not connect to the database: %v\n", err)
+		if cfg.CacheMode == "redis" {
+			log.Fatalf("Could not connect to Redis: %v\n", err)
+		}
+		log.Printf("Could not connect to Redis, caching locally until it is reachable: %v\n", err)
+	}
+	defer rdb.Close()
+
+	dataCache, err := newCache(cfg, rdb)
+	if err != nil {
+		log.Fatalf("Could not create the cache: %v\n", err)
+	}
+	staleFor, err := time.ParseDuration(cfg.CacheStale)
+	if err != nil || staleFor < 0 {
+		log.Fatalf("Invalid CACHE_STALE %q\n", cfg.CacheStale)
+	}
+	handlers.ServeStale(staleFor)
+
+	perInterval, err := time.ParseDuration(cfg.PERInterval)
+	if err != nil || perInterval <= 0 {
+		log.Fatalf("Invalid PER_INTERVAL %q\n", cfg.PERInterval)
 	}
-	defer db.Close()
 
 	runMigrations(db, cfg)
+	// the job reads tables the migrations create
+	go runLeagueConstantsJob(db, dataCache, perInterval)
 
 	router := mux.NewRouter()
-	router.HandleFunc("/add-stat", handlers.AddStatHandler(db, rdb))
-	router.HandleFunc("/stat/players/{playerId}", handlers.GetPlayerAvgStatHandler(db, rdb))
-	router.HandleFunc("/stat/teams/{teamId}", handlers.GetTeamAvgStatHandler(db, rdb))
-	router.HandleFunc("/add-players", handlers.AddPlayerHandler(db, rdb)) // POST /players
-	router.HandleFunc("/players", handlers.ListPlayersHandler(db, rdb))   // GET /players
+	router.HandleFunc("/add-stat", handlers.AddStatHandler(db, dataCache))
+	router.HandleFunc("/stats/{id}", handlers.PatchStatHandler(db, dataCache)).Methods("PATCH")
+	router.HandleFunc("/stats/{id}", handlers.DeleteStatHandler(db, dataCache)).Methods("DELETE")
+	router.HandleFunc("/stat/players/{playerId}", handlers.GetPlayerAvgStatHandler(db, dataCache))
+	router.HandleFunc("/stat/players/{playerId}/advanced", handlers.GetPlayerAdvancedStatHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/stat/players/{playerId}/distribution", handlers.GetPlayerDistributionHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/stat/per", handlers.SeasonPERHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/stat/teams/{teamId}", handlers.GetTeamAvgStatHandler(db, dataCache))
+	router.HandleFunc("/add-players", handlers.AddPlayerHandler(db, dataCache)) // POST /players
+	router.HandleFunc("/players", handlers.ListPlayersHandler(db, dataCache))   // GET /players
+	router.HandleFunc("/games", handlers.AddGameHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/games", handlers.ListGamesHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/games/{gameId}", handlers.GetGameHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/games/{gameId}", handlers.UpdateGameHandler(db, dataCache)).Methods("PUT")
+	router.HandleFunc("/games/{gameId}", handlers.DeleteGameHandler(db, dataCache)).Methods("DELETE")
+	router.HandleFunc("/games/{gameId}/team-stats", handlers.ListTeamGameStatsHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/games/{gameId}/team-stats/{teamId}", handlers.PutTeamGameStatHandler(db, dataCache)).Methods("PUT")
+	router.HandleFunc("/seasons", handlers.AddSeasonHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/seasons", handlers.ListSeasonsHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/teams", handlers.AddTeamHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/teams", handlers.ListTeamsHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/teams/{teamId}", handlers.GetTeamHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/teams/{teamId}", handlers.UpdateTeamHandler(db, dataCache)).Methods("PUT")
+	router.HandleFunc("/teams/{teamId}", handlers.DeleteTeamHandler(db, dataCache)).Methods("DELETE")
+	router.HandleFunc("/transactions", handlers.AddTransactionHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/leagues", handlers.AddLeagueHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/leagues", handlers.ListLeaguesHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/conferences", handlers.AddConferenceHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/conferences", handlers.ListConferencesHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/divisions", handlers.AddDivisionHandler(db, dataCache)).Methods("POST")
+	router.HandleFunc("/divisions", handlers.ListDivisionsHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/standings", handlers.StandingsHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/standings/seeds", handlers.SeedsHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/reports/reconciliation", handlers.ReconciliationReportHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/sources/{source}/players/{externalId}", handlers.UpsertPlayerHandler(db, dataCache)).Methods("PUT")
+	router.HandleFunc("/sources/{source}/teams/{externalId}", handlers.UpsertTeamHandler(db, dataCache)).Methods("PUT")
+	router.HandleFunc("/sources/{source}/games/{externalId}", handlers.UpsertGameHandler(db, dataCache)).Methods("PUT")
+	router.HandleFunc("/sources/{source}/{entity}/{externalId}", handlers.GetExternalIDHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/players/{playerId}", handlers.GetPlayerProfileHandler(db, dataCache)).Methods("GET")
+	router.HandleFunc("/players/{playerId}/teams", handlers.ListPlayerStintsHandler(db, dataCache)).Methods("GET")
 
 	// Swagger endpoint
 	http.Handle("/swagger/", httpSwagger.WrapHandler)
@@ -81,16 +143,49 @@ func initDB(cfg Config) (*sql.DB, error) {
 	return db, nil
 }
 
-// init redis
+// init redis. The client is returned even when Redis cannot be reached, as
+// the tiered cache keeps retrying it.
 func initRedis(cfg Config) (*redis.Client, error) {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort),
 		Password: "", // no password set
 		DB:       0,  // use default DB
 	})
+	if err := rdb.Ping().Err(); err != nil {
+		return rdb, err
+	}
 	return rdb, nil
 }
 
+// runLeagueConstantsJob recomputes the PER of the seasons whose data changed,
+// at start and then every interval
+func runLeagueConstantsJob(db *sql.DB, c cache.Cache, every time.Duration) {
+	for {
+		if err := handlers.RecomputeLeagueConstants(db, c); err != nil {
+			log.Printf("Could not recompute league constants: %v\n", err)
+		}
+		time.Sleep(every)
+	}
+}
+
+// newCache builds the cache named by cfg.CacheMode: redis, lru or tiered,
+// which uses Redis while it is reachable and an in-process LRU otherwise
+func newCache(cfg Config, rdb *redis.Client) (cache.Cache, error) {
+	size, err := strconv.Atoi(cfg.CacheSize)
+	if err != nil || size < 1 {
+		return nil, fmt.Errorf("invalid CACHE_SIZE %q", cfg.CacheSize)
+	}
+	switch cfg.CacheMode {
+	case "redis":
+		return cache.NewRedis(rdb), nil
+	case "lru":
+		return cache.NewLRU(size), nil
+	case "tiered":
+		return cache.NewTiered(cache.NewRedis(rdb), cache.NewLRU(size), 30*time.Second), nil
+	}
+	return nil, fmt.Errorf("invalid CACHE_MODE %q", cfg.CacheMode)
+}
+
 func runMigrations(db *sql.DB, cfg Config) {
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
@@ -0,0 +1,12 @@
+CREATE TABLE games (
+    id SERIAL PRIMARY KEY,
+    home_team_id INTEGER NOT NULL REFERENCES teams(id),
+    away_team_id INTEGER NOT NULL REFERENCES teams(id),
+    game_date DATE NOT NULL,
+    home_score INTEGER NOT NULL DEFAULT 0 CHECK (home_score >= 0),
+    away_score INTEGER NOT NULL DEFAULT 0 CHECK (away_score >= 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'in_progress', 'final', 'postponed')),
+    CHECK (home_team_id <> away_team_id)
+);
+
+CREATE INDEX games_game_date_idx ON games (game_date);
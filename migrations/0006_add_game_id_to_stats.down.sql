@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS stats_game_player_idx;
+
+ALTER TABLE stats DROP CONSTRAINT IF EXISTS fk_game;
+
+ALTER TABLE stats DROP COLUMN IF EXISTS game_id;
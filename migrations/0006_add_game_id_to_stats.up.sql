@@ -0,0 +1,7 @@
+ALTER TABLE stats ADD COLUMN game_id INTEGER;
+
+ALTER TABLE stats
+ADD CONSTRAINT fk_game
+FOREIGN KEY (game_id) REFERENCES games(id);
+
+CREATE UNIQUE INDEX stats_game_player_idx ON stats (game_id, player_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS stats_game_date_idx;
+
+DROP TABLE IF EXISTS seasons;
@@ -0,0 +1,11 @@
+CREATE TABLE seasons (
+    id SERIAL PRIMARY KEY,
+    year INTEGER NOT NULL,
+    season_type VARCHAR(20) NOT NULL CHECK (season_type IN ('preseason', 'regular', 'play-in', 'playoffs')),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    CHECK (start_date <= end_date),
+    UNIQUE (year, season_type)
+);
+
+CREATE INDEX stats_game_date_idx ON stats (game_date);
@@ -0,0 +1,12 @@
+ALTER TABLE stats
+    DROP CONSTRAINT IF EXISTS stats_field_goals_check,
+    DROP CONSTRAINT IF EXISTS stats_three_pointers_check,
+    DROP CONSTRAINT IF EXISTS stats_free_throws_check;
+
+ALTER TABLE stats
+    DROP COLUMN IF EXISTS field_goals_made,
+    DROP COLUMN IF EXISTS field_goals_attempted,
+    DROP COLUMN IF EXISTS three_pointers_made,
+    DROP COLUMN IF EXISTS three_pointers_attempted,
+    DROP COLUMN IF EXISTS free_throws_made,
+    DROP COLUMN IF EXISTS free_throws_attempted;
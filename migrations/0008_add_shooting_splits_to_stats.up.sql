@@ -0,0 +1,16 @@
+ALTER TABLE stats
+    ADD COLUMN field_goals_made INTEGER NOT NULL DEFAULT 0 CHECK (field_goals_made >= 0),
+    ADD COLUMN field_goals_attempted INTEGER NOT NULL DEFAULT 0 CHECK (field_goals_attempted >= 0),
+    ADD COLUMN three_pointers_made INTEGER NOT NULL DEFAULT 0 CHECK (three_pointers_made >= 0),
+    ADD COLUMN three_pointers_attempted INTEGER NOT NULL DEFAULT 0 CHECK (three_pointers_attempted >= 0),
+    ADD COLUMN free_throws_made INTEGER NOT NULL DEFAULT 0 CHECK (free_throws_made >= 0),
+    ADD COLUMN free_throws_attempted INTEGER NOT NULL DEFAULT 0 CHECK (free_throws_attempted >= 0);
+
+ALTER TABLE stats
+    ADD CONSTRAINT stats_field_goals_check CHECK (field_goals_made <= field_goals_attempted),
+    ADD CONSTRAINT stats_three_pointers_check CHECK (
+        three_pointers_made <= three_pointers_attempted
+        AND three_pointers_made <= field_goals_made
+        AND three_pointers_attempted <= field_goals_attempted
+    ),
+    ADD CONSTRAINT stats_free_throws_check CHECK (free_throws_made <= free_throws_attempted);
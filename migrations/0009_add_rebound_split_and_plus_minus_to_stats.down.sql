@@ -0,0 +1,7 @@
+ALTER TABLE stats DROP CONSTRAINT IF EXISTS stats_rebounds_split_check;
+
+ALTER TABLE stats
+    DROP COLUMN IF EXISTS offensive_rebounds,
+    DROP COLUMN IF EXISTS defensive_rebounds,
+    DROP COLUMN IF EXISTS plus_minus,
+    DROP COLUMN IF EXISTS started;
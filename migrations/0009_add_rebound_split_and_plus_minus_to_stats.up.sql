@@ -0,0 +1,10 @@
+ALTER TABLE stats
+    ADD COLUMN offensive_rebounds INTEGER NOT NULL DEFAULT 0 CHECK (offensive_rebounds >= 0),
+    ADD COLUMN defensive_rebounds INTEGER NOT NULL DEFAULT 0 CHECK (defensive_rebounds >= 0),
+    ADD COLUMN plus_minus INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN started BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Rows recorded before the split have no breakdown, so the total is only
+-- checked against it for rows inserted or updated from now on.
+ALTER TABLE stats
+    ADD CONSTRAINT stats_rebounds_split_check CHECK (rebounds = offensive_rebounds + defensive_rebounds) NOT VALID;
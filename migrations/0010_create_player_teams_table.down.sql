@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS stat_teams;
+
+DROP TABLE IF EXISTS player_teams;
@@ -0,0 +1,25 @@
+-- A stint is the period a player was on a team. start_date is inclusive and
+-- end_date exclusive; a NULL start_date means the stint covers every game
+-- before end_date and a NULL end_date that the stint is still open.
+CREATE TABLE player_teams (
+    id SERIAL PRIMARY KEY,
+    player_id INTEGER NOT NULL REFERENCES players(id),
+    team_id INTEGER NOT NULL REFERENCES teams(id),
+    start_date DATE,
+    end_date DATE,
+    CHECK (start_date IS NULL OR end_date IS NULL OR start_date < end_date)
+);
+
+CREATE INDEX player_teams_player_idx ON player_teams (player_id, start_date);
+CREATE UNIQUE INDEX player_teams_open_idx ON player_teams (player_id) WHERE end_date IS NULL;
+
+INSERT INTO player_teams (player_id, team_id)
+SELECT id, team_id FROM players WHERE team_id IS NOT NULL;
+
+-- stat_teams maps every stat line to the team the player was on at game_date
+CREATE VIEW stat_teams AS
+SELECT stats.id AS stat_id, player_teams.team_id
+FROM stats
+JOIN player_teams ON player_teams.player_id = stats.player_id
+    AND (player_teams.start_date IS NULL OR stats.game_date >= player_teams.start_date)
+    AND (player_teams.end_date IS NULL OR stats.game_date < player_teams.end_date);
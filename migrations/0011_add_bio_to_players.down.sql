@@ -0,0 +1,8 @@
+ALTER TABLE players
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS jersey_number,
+    DROP COLUMN IF EXISTS height_cm,
+    DROP COLUMN IF EXISTS weight_kg,
+    DROP COLUMN IF EXISTS birth_date,
+    DROP COLUMN IF EXISTS nationality,
+    DROP COLUMN IF EXISTS draft_year;
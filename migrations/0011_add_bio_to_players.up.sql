@@ -0,0 +1,8 @@
+ALTER TABLE players
+    ADD COLUMN position VARCHAR(3) CHECK (position IN ('PG', 'SG', 'SF', 'PF', 'C', 'G', 'F', 'G-F', 'F-G', 'F-C', 'C-F')),
+    ADD COLUMN jersey_number SMALLINT CHECK (jersey_number BETWEEN 0 AND 99),
+    ADD COLUMN height_cm SMALLINT CHECK (height_cm > 0),
+    ADD COLUMN weight_kg SMALLINT CHECK (weight_kg > 0),
+    ADD COLUMN birth_date DATE,
+    ADD COLUMN nationality CHAR(3),
+    ADD COLUMN draft_year SMALLINT CHECK (draft_year >= 1947);
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS games_overtime_periods_check ON games;
+DROP FUNCTION IF EXISTS check_game_minutes();
+
+DROP TRIGGER IF EXISTS stats_minutes_played_check ON stats;
+DROP FUNCTION IF EXISTS check_stat_minutes();
+
+ALTER TABLE stats DROP CONSTRAINT IF EXISTS stats_minutes_played_check;
+ALTER TABLE stats ADD CONSTRAINT stats_minutes_played_check CHECK (minutes_played >= 0 AND minutes_played <= 48.0);
+
+ALTER TABLE games DROP COLUMN IF EXISTS overtime_periods;
@@ -0,0 +1,39 @@
+ALTER TABLE games ADD COLUMN overtime_periods INTEGER NOT NULL DEFAULT 0 CHECK (overtime_periods >= 0);
+
+-- The upper bound on minutes depends on the game, so it moves from a column
+-- check to triggers on both tables: a game lasts 48 minutes plus 5 per overtime.
+ALTER TABLE stats DROP CONSTRAINT IF EXISTS stats_minutes_played_check;
+ALTER TABLE stats ADD CONSTRAINT stats_minutes_played_check CHECK (minutes_played >= 0);
+
+CREATE FUNCTION check_stat_minutes() RETURNS trigger AS $$
+DECLARE
+    max_minutes FLOAT := 48;
+BEGIN
+    IF NEW.game_id IS NOT NULL THEN
+        SELECT 48 + 5 * overtime_periods INTO max_minutes FROM games WHERE id = NEW.game_id;
+    END IF;
+    IF NEW.minutes_played > max_minutes THEN
+        RAISE EXCEPTION 'minutes_played % exceeds the % minutes the game lasted', NEW.minutes_played, max_minutes
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stats_minutes_played_check
+BEFORE INSERT OR UPDATE OF minutes_played, game_id ON stats
+FOR EACH ROW EXECUTE FUNCTION check_stat_minutes();
+
+CREATE FUNCTION check_game_minutes() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM stats WHERE game_id = NEW.id AND minutes_played > 48 + 5 * NEW.overtime_periods) THEN
+        RAISE EXCEPTION 'game % has stat lines longer than % overtime periods allow', NEW.id, NEW.overtime_periods
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER games_overtime_periods_check
+BEFORE UPDATE OF overtime_periods ON games
+FOR EACH ROW EXECUTE FUNCTION check_game_minutes();
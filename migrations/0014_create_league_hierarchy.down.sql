@@ -0,0 +1,7 @@
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS fk_division;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS division_id;
+
+DROP TABLE IF EXISTS divisions;
+DROP TABLE IF EXISTS conferences;
+DROP TABLE IF EXISTS leagues;
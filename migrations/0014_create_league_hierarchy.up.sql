@@ -0,0 +1,24 @@
+CREATE TABLE leagues (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE
+);
+
+CREATE TABLE conferences (
+    id SERIAL PRIMARY KEY,
+    league_id INTEGER NOT NULL REFERENCES leagues(id),
+    name VARCHAR(100) NOT NULL,
+    UNIQUE (league_id, name)
+);
+
+CREATE TABLE divisions (
+    id SERIAL PRIMARY KEY,
+    conference_id INTEGER NOT NULL REFERENCES conferences(id),
+    name VARCHAR(100) NOT NULL,
+    UNIQUE (conference_id, name)
+);
+
+ALTER TABLE teams ADD COLUMN division_id INTEGER;
+
+ALTER TABLE teams
+ADD CONSTRAINT fk_division
+FOREIGN KEY (division_id) REFERENCES divisions(id);
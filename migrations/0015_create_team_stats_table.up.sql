@@ -0,0 +1,26 @@
+CREATE TABLE team_stats (
+    id SERIAL PRIMARY KEY,
+    game_id INTEGER NOT NULL REFERENCES games(id),
+    team_id INTEGER NOT NULL REFERENCES teams(id),
+    points INTEGER NOT NULL DEFAULT 0 CHECK (points >= 0),
+    rebounds INTEGER NOT NULL DEFAULT 0 CHECK (rebounds >= 0),
+    offensive_rebounds INTEGER NOT NULL DEFAULT 0 CHECK (offensive_rebounds >= 0),
+    defensive_rebounds INTEGER NOT NULL DEFAULT 0 CHECK (defensive_rebounds >= 0),
+    team_rebounds INTEGER NOT NULL DEFAULT 0 CHECK (team_rebounds >= 0 AND team_rebounds <= rebounds),
+    assists INTEGER NOT NULL DEFAULT 0 CHECK (assists >= 0),
+    steals INTEGER NOT NULL DEFAULT 0 CHECK (steals >= 0),
+    blocks INTEGER NOT NULL DEFAULT 0 CHECK (blocks >= 0),
+    fouls INTEGER NOT NULL DEFAULT 0 CHECK (fouls >= 0),
+    turnovers INTEGER NOT NULL DEFAULT 0 CHECK (turnovers >= 0),
+    team_turnovers INTEGER NOT NULL DEFAULT 0 CHECK (team_turnovers >= 0 AND team_turnovers <= turnovers),
+    field_goals_made INTEGER NOT NULL DEFAULT 0 CHECK (field_goals_made >= 0),
+    field_goals_attempted INTEGER NOT NULL DEFAULT 0 CHECK (field_goals_attempted >= field_goals_made),
+    three_pointers_made INTEGER NOT NULL DEFAULT 0 CHECK (three_pointers_made >= 0),
+    three_pointers_attempted INTEGER NOT NULL DEFAULT 0 CHECK (three_pointers_attempted >= three_pointers_made),
+    free_throws_made INTEGER NOT NULL DEFAULT 0 CHECK (free_throws_made >= 0),
+    free_throws_attempted INTEGER NOT NULL DEFAULT 0 CHECK (free_throws_attempted >= free_throws_made),
+    minutes_played FLOAT NOT NULL DEFAULT 0 CHECK (minutes_played >= 0),
+    plus_minus INTEGER NOT NULL DEFAULT 0,
+    CHECK (rebounds = offensive_rebounds + defensive_rebounds),
+    UNIQUE (game_id, team_id)
+);
@@ -0,0 +1,46 @@
+DROP TRIGGER IF EXISTS games_overtime_periods_check ON games;
+CREATE OR REPLACE FUNCTION check_game_minutes() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM stats WHERE game_id = NEW.id AND minutes_played > 48 + 5 * NEW.overtime_periods) THEN
+        RAISE EXCEPTION 'game % has stat lines longer than % overtime periods allow', NEW.id, NEW.overtime_periods
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER games_overtime_periods_check
+BEFORE UPDATE OF overtime_periods ON games
+FOR EACH ROW EXECUTE FUNCTION check_game_minutes();
+
+DROP TRIGGER IF EXISTS stats_minutes_played_check ON stats;
+CREATE OR REPLACE FUNCTION check_stat_minutes() RETURNS trigger AS $$
+DECLARE
+    max_minutes FLOAT := 48;
+BEGIN
+    IF NEW.game_id IS NOT NULL THEN
+        SELECT 48 + 5 * overtime_periods INTO max_minutes FROM games WHERE id = NEW.game_id;
+    END IF;
+    IF NEW.minutes_played > max_minutes THEN
+        RAISE EXCEPTION 'minutes_played % exceeds the % minutes the game lasted', NEW.minutes_played, max_minutes
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER stats_minutes_played_check
+BEFORE INSERT OR UPDATE OF minutes_played, game_id ON stats
+FOR EACH ROW EXECUTE FUNCTION check_stat_minutes();
+
+ALTER TABLE stats DROP CONSTRAINT IF EXISTS stats_fouls_check;
+ALTER TABLE stats ADD CONSTRAINT stats_fouls_check CHECK (fouls >= 0 AND fouls <= 6) NOT VALID;
+
+DROP VIEW IF EXISTS team_rules;
+
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS fk_league;
+ALTER TABLE teams DROP COLUMN IF EXISTS league_id;
+
+ALTER TABLE leagues
+    DROP COLUMN IF EXISTS period_minutes,
+    DROP COLUMN IF EXISTS periods,
+    DROP COLUMN IF EXISTS overtime_minutes,
+    DROP COLUMN IF EXISTS foul_limit;
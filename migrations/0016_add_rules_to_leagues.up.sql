@@ -0,0 +1,81 @@
+ALTER TABLE leagues
+    ADD COLUMN period_minutes FLOAT NOT NULL DEFAULT 12 CHECK (period_minutes > 0),
+    ADD COLUMN periods INTEGER NOT NULL DEFAULT 4 CHECK (periods > 0),
+    ADD COLUMN overtime_minutes FLOAT NOT NULL DEFAULT 5 CHECK (overtime_minutes > 0),
+    ADD COLUMN foul_limit INTEGER NOT NULL DEFAULT 6 CHECK (foul_limit > 0);
+
+ALTER TABLE teams ADD COLUMN league_id INTEGER;
+
+ALTER TABLE teams
+ADD CONSTRAINT fk_league
+FOREIGN KEY (league_id) REFERENCES leagues(id);
+
+UPDATE teams SET league_id = conferences.league_id
+FROM divisions
+JOIN conferences ON conferences.id = divisions.conference_id
+WHERE divisions.id = teams.division_id;
+
+-- The rules every team plays under, falling back to the NBA's for teams
+-- outside any league. A game is played under its home team's rules.
+CREATE VIEW team_rules AS
+SELECT teams.id AS team_id,
+    COALESCE(leagues.period_minutes, 12) AS period_minutes,
+    COALESCE(leagues.periods, 4) AS periods,
+    COALESCE(leagues.overtime_minutes, 5) AS overtime_minutes,
+    COALESCE(leagues.foul_limit, 6) AS foul_limit
+FROM teams
+LEFT JOIN leagues ON leagues.id = teams.league_id;
+
+-- The foul limit depends on the league, so it moves from a column check to
+-- the stat line trigger alongside the minutes limit.
+ALTER TABLE stats DROP CONSTRAINT IF EXISTS stats_fouls_check;
+ALTER TABLE stats ADD CONSTRAINT stats_fouls_check CHECK (fouls >= 0);
+
+CREATE OR REPLACE FUNCTION check_stat_minutes() RETURNS trigger AS $$
+DECLARE
+    max_minutes FLOAT := 48;
+    max_fouls INTEGER := 6;
+BEGIN
+    IF NEW.game_id IS NOT NULL THEN
+        SELECT team_rules.periods * team_rules.period_minutes + team_rules.overtime_minutes * games.overtime_periods,
+            team_rules.foul_limit
+        INTO max_minutes, max_fouls
+        FROM games
+        JOIN team_rules ON team_rules.team_id = games.home_team_id
+        WHERE games.id = NEW.game_id;
+    END IF;
+    IF NEW.minutes_played > max_minutes THEN
+        RAISE EXCEPTION 'minutes_played % exceeds the % minutes the game lasted', NEW.minutes_played, max_minutes
+            USING ERRCODE = 'check_violation';
+    END IF;
+    IF NEW.fouls > max_fouls THEN
+        RAISE EXCEPTION 'fouls % exceeds the league limit of %', NEW.fouls, max_fouls
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stats_minutes_played_check ON stats;
+CREATE TRIGGER stats_minutes_played_check
+BEFORE INSERT OR UPDATE OF minutes_played, fouls, game_id ON stats
+FOR EACH ROW EXECUTE FUNCTION check_stat_minutes();
+
+CREATE OR REPLACE FUNCTION check_game_minutes() RETURNS trigger AS $$
+DECLARE
+    max_minutes FLOAT;
+BEGIN
+    SELECT periods * period_minutes + overtime_minutes * NEW.overtime_periods INTO max_minutes
+    FROM team_rules WHERE team_id = NEW.home_team_id;
+    IF EXISTS (SELECT 1 FROM stats WHERE game_id = NEW.id AND minutes_played > max_minutes) THEN
+        RAISE EXCEPTION 'game % has stat lines longer than % overtime periods allow', NEW.id, NEW.overtime_periods
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS games_overtime_periods_check ON games;
+CREATE TRIGGER games_overtime_periods_check
+BEFORE UPDATE OF overtime_periods, home_team_id ON games
+FOR EACH ROW EXECUTE FUNCTION check_game_minutes();
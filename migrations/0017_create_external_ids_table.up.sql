@@ -0,0 +1,19 @@
+-- Maps the ids other providers use onto our records. Each row names exactly
+-- one player, team or game, and a provider has at most one id per record.
+CREATE TABLE external_ids (
+    id SERIAL PRIMARY KEY,
+    source VARCHAR(50) NOT NULL,
+    external_id VARCHAR(100) NOT NULL,
+    player_id INTEGER REFERENCES players(id) ON DELETE CASCADE,
+    team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+    game_id INTEGER REFERENCES games(id) ON DELETE CASCADE,
+    CHECK (num_nonnulls(player_id, team_id, game_id) = 1)
+);
+
+CREATE UNIQUE INDEX external_ids_player_idx ON external_ids (source, external_id) WHERE player_id IS NOT NULL;
+CREATE UNIQUE INDEX external_ids_team_idx ON external_ids (source, external_id) WHERE team_id IS NOT NULL;
+CREATE UNIQUE INDEX external_ids_game_idx ON external_ids (source, external_id) WHERE game_id IS NOT NULL;
+
+CREATE UNIQUE INDEX external_ids_player_source_idx ON external_ids (source, player_id) WHERE player_id IS NOT NULL;
+CREATE UNIQUE INDEX external_ids_team_source_idx ON external_ids (source, team_id) WHERE team_id IS NOT NULL;
+CREATE UNIQUE INDEX external_ids_game_source_idx ON external_ids (source, game_id) WHERE game_id IS NOT NULL;
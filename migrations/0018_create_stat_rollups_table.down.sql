@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS seasons_rollup ON seasons;
+DROP TRIGGER IF EXISTS player_teams_rollup ON player_teams;
+DROP TRIGGER IF EXISTS stats_rollup ON stats;
+DROP FUNCTION IF EXISTS seasons_rollup();
+DROP FUNCTION IF EXISTS player_teams_rollup();
+DROP FUNCTION IF EXISTS stats_rollup();
+DROP FUNCTION IF EXISTS rebuild_stat_rollups(INTEGER);
+DROP FUNCTION IF EXISTS add_stat_rollup(stats, INTEGER);
+DROP FUNCTION IF EXISTS stat_rollup_season(DATE);
+DROP FUNCTION IF EXISTS stat_rollup_team(INTEGER, DATE);
+DROP TABLE IF EXISTS stat_rollups;
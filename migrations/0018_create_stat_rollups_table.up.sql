@@ -0,0 +1,166 @@
+-- stat_rollups keeps the running sums and counts of the stat lines of every
+-- player, split by the team the player was on and the season the game fell
+-- in, so that averages are read from a handful of rows instead of every stat
+-- line. team_id and season_id are 0 for lines outside any stint or season;
+-- neither is a foreign key, as both are rebuilt when stints or seasons change.
+CREATE TABLE stat_rollups (
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL,
+    season_id INTEGER NOT NULL,
+    games INTEGER NOT NULL,
+    games_started INTEGER NOT NULL,
+    points BIGINT NOT NULL,
+    rebounds BIGINT NOT NULL,
+    offensive_rebounds BIGINT NOT NULL,
+    defensive_rebounds BIGINT NOT NULL,
+    assists BIGINT NOT NULL,
+    steals BIGINT NOT NULL,
+    blocks BIGINT NOT NULL,
+    fouls BIGINT NOT NULL,
+    turnovers BIGINT NOT NULL,
+    field_goals_made BIGINT NOT NULL,
+    field_goals_attempted BIGINT NOT NULL,
+    three_pointers_made BIGINT NOT NULL,
+    three_pointers_attempted BIGINT NOT NULL,
+    free_throws_made BIGINT NOT NULL,
+    free_throws_attempted BIGINT NOT NULL,
+    minutes_played FLOAT NOT NULL,
+    plus_minus BIGINT NOT NULL,
+    PRIMARY KEY (player_id, team_id, season_id)
+);
+
+CREATE INDEX stat_rollups_team_idx ON stat_rollups (team_id, season_id);
+
+-- The rollup a stat line counts towards. Seasons of different types are not
+-- expected to overlap; if they do, the line counts towards the latest one.
+CREATE FUNCTION stat_rollup_team(p_player_id INTEGER, p_game_date DATE) RETURNS INTEGER AS $$
+    SELECT COALESCE((SELECT team_id FROM player_teams
+        WHERE player_id = p_player_id
+          AND (start_date IS NULL OR start_date <= p_game_date)
+          AND (end_date IS NULL OR end_date > p_game_date)
+        LIMIT 1), 0);
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION stat_rollup_season(p_game_date DATE) RETURNS INTEGER AS $$
+    SELECT COALESCE((SELECT id FROM seasons
+        WHERE p_game_date BETWEEN start_date AND end_date
+        ORDER BY start_date DESC
+        LIMIT 1), 0);
+$$ LANGUAGE sql STABLE;
+
+-- add_stat_rollup adds a stat line to its rollup with sign 1 and removes it
+-- with sign -1
+CREATE FUNCTION add_stat_rollup(s stats, sign INTEGER) RETURNS void AS $$
+BEGIN
+    INSERT INTO stat_rollups AS r (player_id, team_id, season_id, games, games_started,
+        points, rebounds, offensive_rebounds, defensive_rebounds, assists, steals, blocks, fouls, turnovers,
+        field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+        free_throws_made, free_throws_attempted, minutes_played, plus_minus)
+    VALUES (s.player_id, stat_rollup_team(s.player_id, s.game_date), stat_rollup_season(s.game_date),
+        sign, sign * s.started::INTEGER,
+        sign * s.points, sign * s.rebounds, sign * s.offensive_rebounds, sign * s.defensive_rebounds,
+        sign * s.assists, sign * s.steals, sign * s.blocks, sign * s.fouls, sign * s.turnovers,
+        sign * s.field_goals_made, sign * s.field_goals_attempted, sign * s.three_pointers_made, sign * s.three_pointers_attempted,
+        sign * s.free_throws_made, sign * s.free_throws_attempted, sign * s.minutes_played, sign * s.plus_minus)
+    ON CONFLICT (player_id, team_id, season_id) DO UPDATE SET
+        games = r.games + EXCLUDED.games,
+        games_started = r.games_started + EXCLUDED.games_started,
+        points = r.points + EXCLUDED.points,
+        rebounds = r.rebounds + EXCLUDED.rebounds,
+        offensive_rebounds = r.offensive_rebounds + EXCLUDED.offensive_rebounds,
+        defensive_rebounds = r.defensive_rebounds + EXCLUDED.defensive_rebounds,
+        assists = r.assists + EXCLUDED.assists,
+        steals = r.steals + EXCLUDED.steals,
+        blocks = r.blocks + EXCLUDED.blocks,
+        fouls = r.fouls + EXCLUDED.fouls,
+        turnovers = r.turnovers + EXCLUDED.turnovers,
+        field_goals_made = r.field_goals_made + EXCLUDED.field_goals_made,
+        field_goals_attempted = r.field_goals_attempted + EXCLUDED.field_goals_attempted,
+        three_pointers_made = r.three_pointers_made + EXCLUDED.three_pointers_made,
+        three_pointers_attempted = r.three_pointers_attempted + EXCLUDED.three_pointers_attempted,
+        free_throws_made = r.free_throws_made + EXCLUDED.free_throws_made,
+        free_throws_attempted = r.free_throws_attempted + EXCLUDED.free_throws_attempted,
+        minutes_played = r.minutes_played + EXCLUDED.minutes_played,
+        plus_minus = r.plus_minus + EXCLUDED.plus_minus;
+
+    DELETE FROM stat_rollups
+    WHERE player_id = s.player_id AND games = 0;
+END;
+$$ LANGUAGE plpgsql;
+
+-- rebuild_stat_rollups recomputes a player's rollups from the stat lines,
+-- for when the stints or seasons the lines fall in change
+CREATE FUNCTION rebuild_stat_rollups(p_player_id INTEGER) RETURNS void AS $$
+BEGIN
+    DELETE FROM stat_rollups WHERE player_id = p_player_id;
+    INSERT INTO stat_rollups (player_id, team_id, season_id, games, games_started,
+        points, rebounds, offensive_rebounds, defensive_rebounds, assists, steals, blocks, fouls, turnovers,
+        field_goals_made, field_goals_attempted, three_pointers_made, three_pointers_attempted,
+        free_throws_made, free_throws_attempted, minutes_played, plus_minus)
+    SELECT player_id, stat_rollup_team(player_id, game_date), stat_rollup_season(game_date),
+        COUNT(*), COUNT(*) FILTER (WHERE started),
+        SUM(points), SUM(rebounds), SUM(offensive_rebounds), SUM(defensive_rebounds),
+        SUM(assists), SUM(steals), SUM(blocks), SUM(fouls), SUM(turnovers),
+        SUM(field_goals_made), SUM(field_goals_attempted), SUM(three_pointers_made), SUM(three_pointers_attempted),
+        SUM(free_throws_made), SUM(free_throws_attempted), SUM(minutes_played), SUM(plus_minus)
+    FROM stats
+    WHERE player_id = p_player_id
+    GROUP BY 1, 2, 3;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION stats_rollup() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM add_stat_rollup(OLD, -1);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM add_stat_rollup(NEW, 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stats_rollup
+AFTER INSERT OR UPDATE OR DELETE ON stats
+FOR EACH ROW EXECUTE FUNCTION stats_rollup();
+
+-- A changed stint moves the player's lines between teams
+CREATE FUNCTION player_teams_rollup() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM rebuild_stat_rollups(OLD.player_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.player_id <> OLD.player_id) THEN
+        PERFORM rebuild_stat_rollups(NEW.player_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER player_teams_rollup
+AFTER INSERT OR UPDATE OR DELETE ON player_teams
+FOR EACH ROW EXECUTE FUNCTION player_teams_rollup();
+
+-- A changed season moves the lines in its old and new date ranges between
+-- seasons
+CREATE FUNCTION seasons_rollup() RETURNS trigger AS $$
+DECLARE
+    p_player_id INTEGER;
+BEGIN
+    FOR p_player_id IN
+        SELECT DISTINCT player_id FROM stats
+        WHERE (TG_OP <> 'INSERT' AND game_date BETWEEN OLD.start_date AND OLD.end_date)
+           OR (TG_OP <> 'DELETE' AND game_date BETWEEN NEW.start_date AND NEW.end_date)
+    LOOP
+        PERFORM rebuild_stat_rollups(p_player_id);
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER seasons_rollup
+AFTER INSERT OR UPDATE OR DELETE ON seasons
+FOR EACH ROW EXECUTE FUNCTION seasons_rollup();
+
+SELECT rebuild_stat_rollups(id) FROM players;
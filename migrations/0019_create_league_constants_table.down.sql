@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS seasons_league_constants ON seasons;
+DROP TRIGGER IF EXISTS player_teams_league_constants ON player_teams;
+DROP TRIGGER IF EXISTS games_league_constants ON games;
+DROP TRIGGER IF EXISTS team_stats_league_constants ON team_stats;
+DROP TRIGGER IF EXISTS stats_league_constants ON stats;
+DROP FUNCTION IF EXISTS seasons_league_constants();
+DROP FUNCTION IF EXISTS player_teams_league_constants();
+DROP FUNCTION IF EXISTS games_league_constants();
+DROP FUNCTION IF EXISTS team_stats_league_constants();
+DROP FUNCTION IF EXISTS stats_league_constants();
+DROP FUNCTION IF EXISTS touch_league_constants(DATE, DATE);
+DROP TABLE IF EXISTS player_efficiency;
+DROP TABLE IF EXISTS league_constants;
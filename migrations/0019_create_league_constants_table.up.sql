@@ -0,0 +1,122 @@
+-- league_constants holds the league-wide values PER is scaled by, computed
+-- per season by a background job from every stat line of the season. Writes
+-- that change a season's data bump version, and the job recomputes the
+-- seasons whose computed_version is behind it.
+CREATE TABLE league_constants (
+    season_id INTEGER PRIMARY KEY REFERENCES seasons(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL DEFAULT 1,
+    computed_version INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ,
+    factor FLOAT NOT NULL DEFAULT 0,
+    value_of_possession FLOAT NOT NULL DEFAULT 0,
+    defensive_rebound_pct FLOAT NOT NULL DEFAULT 0,
+    pace FLOAT NOT NULL DEFAULT 0,
+    free_throws_per_foul FLOAT NOT NULL DEFAULT 0,
+    free_throw_attempts_per_foul FLOAT NOT NULL DEFAULT 0,
+    average_aper FLOAT NOT NULL DEFAULT 0
+);
+
+-- player_efficiency is each player's PER for a season, written by the same
+-- job as the season's constants
+CREATE TABLE player_efficiency (
+    season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    minutes_played FLOAT NOT NULL,
+    uper FLOAT NOT NULL,
+    aper FLOAT NOT NULL,
+    per FLOAT NOT NULL,
+    PRIMARY KEY (season_id, player_id)
+);
+
+INSERT INTO league_constants (season_id) SELECT id FROM seasons;
+
+-- touch_league_constants marks the seasons overlapping the dates from p_from
+-- up to p_to as changed. NULL bounds are open.
+CREATE FUNCTION touch_league_constants(p_from DATE, p_to DATE) RETURNS void AS $$
+    UPDATE league_constants SET version = version + 1
+    WHERE season_id IN (
+        SELECT id FROM seasons
+        WHERE (p_from IS NULL OR end_date >= p_from)
+          AND (p_to IS NULL OR start_date < p_to)
+    );
+$$ LANGUAGE sql;
+
+CREATE FUNCTION stats_league_constants() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM touch_league_constants(OLD.game_date, OLD.game_date + 1);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.game_date <> OLD.game_date) THEN
+        PERFORM touch_league_constants(NEW.game_date, NEW.game_date + 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stats_league_constants
+AFTER INSERT OR UPDATE OR DELETE ON stats
+FOR EACH ROW EXECUTE FUNCTION stats_league_constants();
+
+-- Team box scores are the team context of PER
+CREATE FUNCTION team_stats_league_constants() RETURNS trigger AS $$
+DECLARE
+    d DATE;
+BEGIN
+    FOR d IN
+        SELECT game_date FROM games
+        WHERE (TG_OP <> 'INSERT' AND id = OLD.game_id)
+           OR (TG_OP <> 'DELETE' AND id = NEW.game_id)
+    LOOP
+        PERFORM touch_league_constants(d, d + 1);
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER team_stats_league_constants
+AFTER INSERT OR UPDATE OR DELETE ON team_stats
+FOR EACH ROW EXECUTE FUNCTION team_stats_league_constants();
+
+-- A game's teams decide whose totals its lines are measured against
+CREATE FUNCTION games_league_constants() RETURNS trigger AS $$
+BEGIN
+    PERFORM touch_league_constants(OLD.game_date, OLD.game_date + 1);
+    IF NEW.game_date <> OLD.game_date THEN
+        PERFORM touch_league_constants(NEW.game_date, NEW.game_date + 1);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER games_league_constants
+AFTER UPDATE OF game_date, home_team_id, away_team_id ON games
+FOR EACH ROW EXECUTE FUNCTION games_league_constants();
+
+-- A changed stint moves lines between teams
+CREATE FUNCTION player_teams_league_constants() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM touch_league_constants(OLD.start_date, OLD.end_date);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM touch_league_constants(NEW.start_date, NEW.end_date);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER player_teams_league_constants
+AFTER INSERT OR UPDATE OR DELETE ON player_teams
+FOR EACH ROW EXECUTE FUNCTION player_teams_league_constants();
+
+CREATE FUNCTION seasons_league_constants() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO league_constants (season_id) VALUES (NEW.id)
+    ON CONFLICT (season_id) DO UPDATE SET version = league_constants.version + 1;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER seasons_league_constants
+AFTER INSERT OR UPDATE ON seasons
+FOR EACH ROW EXECUTE FUNCTION seasons_league_constants();
@@ -6,33 +6,413 @@ import (
 
 // Player represents a basketball player
 type Player struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	TeamID int    `json:"team_id"` // New field for foreign key
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	TeamID       int        `json:"team_id"`            // New field for foreign key
+	Position     string     `json:"position,omitempty"` // PG, SG, SF, PF, C, G, F or a hybrid such as G-F
+	JerseyNumber *int       `json:"jersey_number,omitempty"`
+	HeightCm     *int       `json:"height_cm,omitempty"`
+	WeightKg     *int       `json:"weight_kg,omitempty"`
+	BirthDate    *time.Time `json:"birth_date,omitempty"`
+	Nationality  string     `json:"nationality,omitempty"` // ISO 3166-1 alpha-3 country code
+	DraftYear    *int       `json:"draft_year,omitempty"`
+}
+
+// PlayerProfile is a player's bio with their career and current regular
+// season averages. Either average is omitted when there are no stat lines.
+type PlayerProfile struct {
+	Player
+	Career        *AvgStat `json:"career,omitempty"`
+	CurrentSeason *AvgStat `json:"current_season,omitempty"`
+}
+
+// PlayerStint is a period a player spent on a team. StartDate is inclusive
+// and nil for a stint that covers all earlier games; EndDate is exclusive and
+// nil while the player is still on the team.
+type PlayerStint struct {
+	ID        int        `json:"id"`
+	PlayerID  int        `json:"player_id"`
+	TeamID    int        `json:"team_id"`
+	StartDate *time.Time `json:"start_date"`
+	EndDate   *time.Time `json:"end_date"`
+}
+
+// Transaction moves a player to ToTeamID from EffectiveDate onwards. A zero
+// ToTeamID releases the player.
+type Transaction struct {
+	PlayerID      int       `json:"player_id"`
+	FromTeamID    int       `json:"from_team_id"` // filled in from the player's open stint
+	ToTeamID      int       `json:"to_team_id"`
+	EffectiveDate time.Time `json:"effective_date"`
+}
+
+// Team represents a basketball team
+type Team struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	City       string   `json:"city"`
+	DivisionID int      `json:"division_id,omitempty"`
+	LeagueID   int      `json:"league_id,omitempty"` // taken from the division when omitted
+	Players    []Player `json:"players,omitempty"`   // roster, only filled in when fetching a single team
+}
+
+// League is the top of the league → conference → division → team hierarchy.
+// Its rules apply to every game whose home team plays in it.
+type League struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Rules
+}
+
+// Rules are the parameters of a league's game that stat lines are validated
+// and normalised against
+type Rules struct {
+	PeriodMinutes   float64 `json:"period_minutes"`
+	Periods         int     `json:"periods"`
+	OvertimeMinutes float64 `json:"overtime_minutes"`
+	FoulLimit       int     `json:"foul_limit"` // personal fouls that disqualify a player
+}
+
+// DefaultRules are the NBA's, used for teams outside any league
+var DefaultRules = Rules{PeriodMinutes: 12, Periods: 4, OvertimeMinutes: 5, FoulLimit: 6}
+
+// RegulationMinutes is the length of a game without overtime
+func (r Rules) RegulationMinutes() float64 {
+	return r.PeriodMinutes * float64(r.Periods)
+}
+
+// GameMinutes is the length of a game with the given number of overtime
+// periods. No player can log more than this.
+func (r Rules) GameMinutes(overtimePeriods int) float64 {
+	return r.RegulationMinutes() + r.OvertimeMinutes*float64(overtimePeriods)
+}
+
+// PeriodLength returns the length of a period, counting overtimes on from
+// the last period of regulation
+func (r Rules) PeriodLength(period int) float64 {
+	if period <= r.Periods {
+		return r.PeriodMinutes
+	}
+	return r.OvertimeMinutes
+}
+
+// Conference groups divisions within a league
+type Conference struct {
+	ID       int    `json:"id"`
+	LeagueID int    `json:"league_id"`
+	Name     string `json:"name"`
+}
+
+// Division groups teams within a conference
+type Division struct {
+	ID           int    `json:"id"`
+	ConferenceID int    `json:"conference_id"`
+	Name         string `json:"name"`
+}
+
+// Game represents a game between two teams
+type Game struct {
+	ID              int       `json:"id"`
+	HomeTeamID      int       `json:"home_team_id"`
+	AwayTeamID      int       `json:"away_team_id"`
+	GameDate        time.Time `json:"game_date"`
+	HomeScore       int       `json:"home_score"`
+	AwayScore       int       `json:"away_score"`
+	Status          string    `json:"status"`           // scheduled, in_progress, final or postponed
+	OvertimePeriods int       `json:"overtime_periods"` // number of overtime periods played
+}
+
+// Season represents one phase of a league year. Year is the calendar year the
+// season ends in, so 2023-24 is 2024.
+type Season struct {
+	ID        int       `json:"id"`
+	Year      int       `json:"year"`
+	Type      string    `json:"type"` // preseason, regular, play-in or playoffs
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// BoxScore holds the counting stats recorded for a player over a game or a
+// single period of one
+type BoxScore struct {
+	Points                 int     `json:"points"`
+	Rebounds               int     `json:"rebounds"` // must equal offensive + defensive; derived when omitted
+	OffensiveRebounds      int     `json:"offensive_rebounds"`
+	DefensiveRebounds      int     `json:"defensive_rebounds"`
+	Assists                int     `json:"assists"`
+	Steals                 int     `json:"steals"`
+	Blocks                 int     `json:"blocks"`
+	Fouls                  int     `json:"fouls"`
+	Turnovers              int     `json:"turnovers"`
+	FieldGoalsMade         int     `json:"field_goals_made"`
+	FieldGoalsAttempted    int     `json:"field_goals_attempted"`
+	ThreePointersMade      int     `json:"three_pointers_made"`
+	ThreePointersAttempted int     `json:"three_pointers_attempted"`
+	FreeThrowsMade         int     `json:"free_throws_made"`
+	FreeThrowsAttempted    int     `json:"free_throws_attempted"`
+	MinutesPlayed          float64 `json:"minutes_played"`
+	PlusMinus              int     `json:"plus_minus"`
+}
+
+// Add returns the column-wise sum of b and o
+func (b BoxScore) Add(o BoxScore) BoxScore {
+	return BoxScore{
+		Points:                 b.Points + o.Points,
+		Rebounds:               b.Rebounds + o.Rebounds,
+		OffensiveRebounds:      b.OffensiveRebounds + o.OffensiveRebounds,
+		DefensiveRebounds:      b.DefensiveRebounds + o.DefensiveRebounds,
+		Assists:                b.Assists + o.Assists,
+		Steals:                 b.Steals + o.Steals,
+		Blocks:                 b.Blocks + o.Blocks,
+		Fouls:                  b.Fouls + o.Fouls,
+		Turnovers:              b.Turnovers + o.Turnovers,
+		FieldGoalsMade:         b.FieldGoalsMade + o.FieldGoalsMade,
+		FieldGoalsAttempted:    b.FieldGoalsAttempted + o.FieldGoalsAttempted,
+		ThreePointersMade:      b.ThreePointersMade + o.ThreePointersMade,
+		ThreePointersAttempted: b.ThreePointersAttempted + o.ThreePointersAttempted,
+		FreeThrowsMade:         b.FreeThrowsMade + o.FreeThrowsMade,
+		FreeThrowsAttempted:    b.FreeThrowsAttempted + o.FreeThrowsAttempted,
+		MinutesPlayed:          b.MinutesPlayed + o.MinutesPlayed,
+		PlusMinus:              b.PlusMinus + o.PlusMinus,
+	}
 }
 
 // GameStat represents the statistics of a player in a game
 type GameStat struct {
-	PlayerID      int       `json:"player_id"`
-	Points        int       `json:"points"`
-	Rebounds      int       `json:"rebounds"`
-	Assists       int       `json:"assists"`
-	Steals        int       `json:"steals"`
-	Blocks        int       `json:"blocks"`
-	Fouls         int       `json:"fouls"`
-	Turnovers     int       `json:"turnovers"`
-	MinutesPlayed float64   `json:"minutes_played"`
-	GameDate      time.Time `json:"game_date"`
-}
-
-// AvgStat
+	ID       int `json:"id"`
+	PlayerID int `json:"player_id"`
+	GameID   int `json:"game_id"`
+	BoxScore
+	Started  bool      `json:"started"`
+	GameDate time.Time `json:"game_date"`
+	// Periods optionally breaks the line down by period. When given, the game
+	// totals are rolled up from them if omitted and must match them otherwise.
+	Periods []PeriodStat `json:"periods,omitempty"`
+}
+
+// PeriodStat is a player's line for one period of a game. Periods 1 to 4 are
+// the quarters and 5 onwards the overtimes.
+type PeriodStat struct {
+	Period int `json:"period"`
+	BoxScore
+}
+
+// TeamGameStat is a team's box score for one game. The BoxScore columns are
+// team totals; TeamRebounds and TeamTurnovers are the part of those totals
+// not credited to any player.
+type TeamGameStat struct {
+	TeamID int `json:"team_id"`
+	GameID int `json:"game_id"`
+	BoxScore
+	TeamRebounds  int `json:"team_rebounds"`
+	TeamTurnovers int `json:"team_turnovers"`
+}
+
+// AvgStat holds a set of stat lines' totals expressed per game, per 36
+// minutes, per 100 possessions or as totals, as named by Per
 type AvgStat struct {
-	AvgPoints        float64 `json:"avg_points"`
-	AvgRebounds      float64 `json:"avg_rebounds"`
-	AvgAssists       float64 `json:"avg_assists"`
-	AvgSteals        float64 `json:"avg_steals"`
-	AvgBlocks        float64 `json:"avg_blocks"`
-	AvgFouls         float64 `json:"avg_fouls"`
-	AvgTurnovers     float64 `json:"avg_turnovers"`
-	AvgMinutesPlayed float64 `json:"avg_minutes_played"`
+	AvgPoints                 float64 `json:"avg_points"`
+	AvgRebounds               float64 `json:"avg_rebounds"`
+	AvgOffensiveRebounds      float64 `json:"avg_offensive_rebounds"`
+	AvgDefensiveRebounds      float64 `json:"avg_defensive_rebounds"`
+	AvgAssists                float64 `json:"avg_assists"`
+	AvgSteals                 float64 `json:"avg_steals"`
+	AvgBlocks                 float64 `json:"avg_blocks"`
+	AvgFouls                  float64 `json:"avg_fouls"`
+	AvgTurnovers              float64 `json:"avg_turnovers"`
+	AvgFieldGoalsMade         float64 `json:"avg_field_goals_made"`
+	AvgFieldGoalsAttempted    float64 `json:"avg_field_goals_attempted"`
+	AvgThreePointersMade      float64 `json:"avg_three_pointers_made"`
+	AvgThreePointersAttempted float64 `json:"avg_three_pointers_attempted"`
+	AvgFreeThrowsMade         float64 `json:"avg_free_throws_made"`
+	AvgFreeThrowsAttempted    float64 `json:"avg_free_throws_attempted"`
+	AvgMinutesPlayed          float64 `json:"avg_minutes_played"`
+	AvgPlusMinus              float64 `json:"avg_plus_minus"`
+	GamesPlayed               int     `json:"games_played"`
+	GamesStarted              int     `json:"games_started"`
+	// Shooting percentages are total makes over total attempts, not the
+	// average of each game's percentage.
+	FieldGoalPct  float64 `json:"field_goal_pct"`
+	ThreePointPct float64 `json:"three_point_pct"`
+	FreeThrowPct  float64 `json:"free_throw_pct"`
+	Per           string  `json:"per"` // game, 36, 100poss or total
+	// PerMinutes is the minutes per 36 scales to under the leagues' rules,
+	// 36 for NBA games
+	PerMinutes float64 `json:"per_minutes,omitempty"`
+}
+
+// AdvancedStat holds efficiency metrics computed from a player's totals and
+// those of the player's team and its opponents in the same games. Like the
+// shooting percentages of AvgStat, percentages are fractions of 1.
+type AdvancedStat struct {
+	GamesPlayed           int     `json:"games_played"`
+	TrueShootingPct       float64 `json:"true_shooting_pct"`
+	EffectiveFieldGoalPct float64 `json:"effective_field_goal_pct"`
+	// UsagePct is the share of the team's possessions the player used while
+	// on the floor
+	UsagePct float64 `json:"usage_pct"`
+	// AssistPct is the share of teammates' field goals the player assisted
+	// while on the floor
+	AssistPct float64 `json:"assist_pct"`
+	// ReboundPct is the share of available rebounds the player grabbed while
+	// on the floor
+	ReboundPct  float64 `json:"rebound_pct"`
+	TurnoverPct float64 `json:"turnover_pct"`
+	// GameScore is Hollinger's game score averaged per game
+	GameScore float64 `json:"game_score"`
+}
+
+// Distribution describes how a stat column is spread over a set of stat
+// lines. StdDev is the sample standard deviation, and Percentiles are keyed
+// by percentile, e.g. "p90".
+type Distribution struct {
+	Sum         float64            `json:"sum"`
+	Count       int                `json:"count"`
+	Median      float64            `json:"median"`
+	StdDev      float64            `json:"stddev"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// StatDistribution holds the distribution of every box score column of a
+// player's stat lines, keyed by column
+type StatDistribution struct {
+	GamesPlayed int                     `json:"games_played"`
+	Stats       map[string]Distribution `json:"stats"`
+}
+
+// LeagueConstants are the league-wide values of a season that PER is scaled
+// by, computed from every stat line of the season
+type LeagueConstants struct {
+	Factor                   float64 `json:"factor"`
+	ValueOfPossession        float64 `json:"value_of_possession"`
+	DefensiveReboundPct      float64 `json:"defensive_rebound_pct"`
+	Pace                     float64 `json:"pace"` // possessions per 48 minutes
+	FreeThrowsPerFoul        float64 `json:"free_throws_per_foul"`
+	FreeThrowAttemptsPerFoul float64 `json:"free_throw_attempts_per_foul"`
+	// AverageAPER is the minute-weighted league average of aPER, which PER
+	// scales to 15
+	AverageAPER float64    `json:"average_aper"`
+	ComputedAt  *time.Time `json:"computed_at"`
+	// Stale is set when the season's data changed since the constants were
+	// computed and a recompute is pending
+	Stale bool `json:"stale"`
+}
+
+// PlayerPER is a player's Player Efficiency Rating over a season: unadjusted,
+// adjusted for the pace of the player's teams, and scaled to a league
+// average of 15
+type PlayerPER struct {
+	PlayerID      int     `json:"player_id"`
+	PlayerName    string  `json:"player_name"`
+	MinutesPlayed float64 `json:"minutes_played"`
+	UnadjustedPER float64 `json:"uper"`
+	AdjustedPER   float64 `json:"aper"`
+	PER           float64 `json:"per"`
+}
+
+// SeasonPER lists the PER of every player with minutes in a season, highest
+// first
+type SeasonPER struct {
+	Season  Season          `json:"season"`
+	League  LeagueConstants `json:"league"`
+	Players []PlayerPER     `json:"players"`
+}
+
+// TeamRecord is a team's record over a season, derived from final scores
+type TeamRecord struct {
+	TeamID        int     `json:"team_id"`
+	TeamName      string  `json:"team_name"`
+	ConferenceID  int     `json:"conference_id"`
+	DivisionID    int     `json:"division_id"`
+	Division      string  `json:"division"`
+	Wins          int     `json:"wins"`
+	Losses        int     `json:"losses"`
+	WinPct        float64 `json:"win_pct"`
+	GamesBehind   float64 `json:"games_behind"` // behind the conference leader
+	HomeWins      int     `json:"home_wins"`
+	HomeLosses    int     `json:"home_losses"`
+	AwayWins      int     `json:"away_wins"`
+	AwayLosses    int     `json:"away_losses"`
+	LastTen       string  `json:"last_10"` // e.g. "7-3"
+	Streak        string  `json:"streak"`  // e.g. "W3"
+	PointsFor     int     `json:"points_for"`
+	PointsAgainst int     `json:"points_against"`
+}
+
+// ConferenceStandings ranks the teams of one conference by win percentage
+type ConferenceStandings struct {
+	ConferenceID int          `json:"conference_id"`
+	Name         string       `json:"name"`
+	Teams        []TeamRecord `json:"teams"`
+}
+
+// Standings are the conference tables for one season
+type Standings struct {
+	Season      Season                `json:"season"`
+	Conferences []ConferenceStandings `json:"conferences"`
+}
+
+// Seed is a team's place in its conference's seeding. Tiebreak names the
+// rule that put the team ahead of the next team when they had the same
+// record, and is empty when the records differ.
+type Seed struct {
+	Seed int `json:"seed"`
+	TeamRecord
+	Tiebreak string `json:"tiebreak,omitempty"`
+}
+
+// ConferenceSeeds is the fully ordered seed list of one conference
+type ConferenceSeeds struct {
+	ConferenceID int    `json:"conference_id"`
+	Name         string `json:"name"`
+	Seeds        []Seed `json:"seeds"`
+}
+
+// SeedList is the seeding of every conference for one season
+type SeedList struct {
+	Season      Season            `json:"season"`
+	Conferences []ConferenceSeeds `json:"conferences"`
+}
+
+// ReconciliationIssue is one side of a final game whose lines disagree with
+// its final score. Check is player_points when the player lines do not add
+// up to the score and team_points when the team box score does not match it.
+type ReconciliationIssue struct {
+	GameID      int       `json:"game_id"`
+	GameDate    time.Time `json:"game_date"`
+	TeamID      int       `json:"team_id"`
+	Check       string    `json:"check"`
+	FinalScore  int       `json:"final_score"`
+	Points      int       `json:"points"`
+	PlayerLines int       `json:"player_lines"`
+}
+
+// DataQualityReport lists the reconciliation issues found over the final
+// games checked
+type DataQualityReport struct {
+	GamesChecked int                   `json:"games_checked"`
+	Issues       []ReconciliationIssue `json:"issues"`
+}
+
+// ExternalID maps the id a provider uses for a player, team or game onto
+// ours. Entity is players, teams or games.
+type ExternalID struct {
+	Source     string `json:"source"`
+	Entity     string `json:"entity"`
+	ExternalID string `json:"external_id"`
+	InternalID int    `json:"internal_id"`
+}
+
+// FieldError describes one field of a request body that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationError is the body of a 422 response and lists every failed field
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
 }